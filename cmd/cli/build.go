@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/project"
+)
+
+func newBuildCmd() *cobra.Command {
+	buildCmd := &cobra.Command{
+		Use:   "build [dir]",
+		Short: "Build the project described by watchface.yaml or watchface.json",
+		Long: `Build a watchface package from a project directory.
+
+The directory (default ".") must contain watchface.yaml, watchface.yml or
+watchface.json. Flags override individual fields of the project file.
+
+Examples:
+  watchface-builder build
+  watchface-builder build ./my-face --version 1.1.0 --output ./dist`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runProjectBuild,
+	}
+
+	addBuildFlags(buildCmd.Flags())
+
+	return buildCmd
+}
+
+func runProjectBuild(cmd *cobra.Command, args []string) error {
+	printBanner()
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	projectPath, err := project.Find(dir)
+	if err != nil {
+		return err
+	}
+	p, err := project.Load(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := applyFlagOverrides(cmd.Flags(), p); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project file %s: %w", projectPath, err)
+	}
+
+	options, err := p.BuildOptions()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(msg("build.project", projectPath))
+	executeBuild(options)
+	return nil
+}
+
+// applyFlagOverrides copies explicitly set flags onto the project descriptor
+func applyFlagOverrides(flags *pflag.FlagSet, p *project.Project) error {
+	if flags.Changed("name") {
+		p.Name = name
+	}
+	if flags.Changed("version") {
+		p.Version = version
+	}
+	if flags.Changed("author") {
+		p.Author = author
+	}
+	if flags.Changed("description") {
+		p.Description = description
+	}
+	if flags.Changed("template") {
+		p.Template = template
+	}
+	if flags.Changed("device") {
+		p.Devices = devices
+	}
+	if flags.Changed("permission") {
+		p.Permissions = permissions
+	}
+	if flags.Changed("complication") {
+		parsed, err := parseComplications()
+		if err != nil {
+			return err
+		}
+		p.Complications = parsed
+	}
+	if flags.Changed("locale") {
+		p.Locales = localeTags
+	}
+	themeOverrides, err := parseThemeFlags()
+	if err != nil {
+		return err
+	}
+	if themeOverrides != (builder.Theme{}) {
+		var theme builder.Theme
+		if p.Theme != nil {
+			theme = *p.Theme
+		}
+		theme = theme.Merge(themeOverrides)
+		p.Theme = &theme
+	}
+	if flags.Changed("tags") {
+		p.Tags = parseTags(tags)
+	}
+	if flags.Changed("no-preview") {
+		preview := !noPreview
+		p.GeneratePreview = &preview
+	}
+	if flags.Changed("include") {
+		p.Include = includes
+	}
+	if flags.Changed("exclude") {
+		p.Exclude = excludes
+	}
+	if flags.Changed("store-images") {
+		p.StoreImages = storeImages
+	}
+	if flags.Changed("minify-html") {
+		p.MinifyHTML = minifyHTML
+	}
+	if flags.Changed("minify-css") {
+		p.MinifyCSS = minifyCSS
+	}
+	if flags.Changed("minify-js") {
+		p.MinifyJS = minifyJS
+	}
+	if flags.Changed("optimize-images") {
+		p.OptimizeImages = optimizeImages
+	}
+	if flags.Changed("inline") {
+		p.Inline = inline
+	}
+	if flags.Changed("reproducible") {
+		p.Reproducible = reproducible
+	}
+	if flags.Changed("custom-html") {
+		p.CustomHTML, p.HTMLFile = customHTML, ""
+	}
+	if flags.Changed("custom-css") {
+		p.CustomCSS, p.CSSFile = customCSS, ""
+	}
+	if flags.Changed("custom-js") {
+		p.CustomJS, p.JSFile = customJS, ""
+	}
+
+	// Paths given on the command line are relative to the working directory,
+	// not to the project directory
+	pathFlags := []struct {
+		flag   string
+		value  string
+		target *string
+		inline *string
+	}{
+		{"output", output, &p.Output, nil},
+		{"source", sourceDir, &p.Source, nil},
+		{"theme-file", themeFile, &p.ThemeFile, nil},
+		{"font", fontFile, &p.Font, nil},
+		{"custom-html-file", customHTMLFile, &p.HTMLFile, &p.CustomHTML},
+		{"custom-css-file", customCSSFile, &p.CSSFile, &p.CustomCSS},
+		{"custom-js-file", customJSFile, &p.JSFile, &p.CustomJS},
+	}
+	for _, pathFlag := range pathFlags {
+		if !flags.Changed(pathFlag.flag) {
+			continue
+		}
+		absPath, err := filepath.Abs(pathFlag.value)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %w", pathFlag.flag, err)
+		}
+		*pathFlag.target = absPath
+		if pathFlag.inline != nil {
+			*pathFlag.inline = ""
+		}
+	}
+	return nil
+}
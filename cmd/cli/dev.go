@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/devserver"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/project"
+)
+
+var (
+	devHost     string
+	devPort     int
+	devDevice   string
+	devData     string
+	devInterval time.Duration
+)
+
+func newDevCmd() *cobra.Command {
+	devCmd := &cobra.Command{
+		Use:   "dev [dir]",
+		Short: "Serve a project in a watch simulator that reloads on every change",
+		Long: `Serve a watchface project for development.
+
+The face is shown inside a simulator page that frames it in the selected
+device's shape and size. Project files are polled for changes, and the face is
+regenerated and reloaded in the browser on every save. Faces that read watch data
+through watchface.js get mock values from watchface.data.json.
+
+Examples:
+  watchface-builder dev
+  watchface-builder dev ./my-face --device square-320 --port 3000`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runDev,
+	}
+
+	devCmd.Flags().StringVar(&devHost, "host", "localhost", "Host address to listen on")
+	devCmd.Flags().IntVarP(&devPort, "port", "p", 8090, "Port to listen on")
+	devCmd.Flags().StringVar(&devDevice, "device", "", "Device shown by default (default the project's first device)")
+	devCmd.Flags().StringVar(&devData, "data", "", "Mock watch data fixture (default watchface.data.json in the project)")
+	devCmd.Flags().DurationVar(&devInterval, "interval", devserver.DefaultInterval, "How often to check files for changes")
+
+	return devCmd
+}
+
+func runDev(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	projectPath, err := project.Find(dir)
+	if err != nil {
+		return err
+	}
+	if projectPath, err = filepath.Abs(projectPath); err != nil {
+		return err
+	}
+	if devDevice != "" {
+		if _, ok := builder.LookupDevice(devDevice); !ok {
+			return fmt.Errorf("unknown device: %s", devDevice)
+		}
+	}
+
+	dev := devserver.New(builder.NewBuilder(), devserver.Config{
+		ProjectPath: projectPath,
+		Device:      devDevice,
+		DataPath:    devData,
+		Interval:    devInterval,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+		},
+	})
+	if err := dev.Err(); err != nil {
+		fmt.Println(msg("error", err))
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	go dev.Watch(ctx)
+
+	addr := net.JoinHostPort(devHost, strconv.Itoa(devPort))
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           dev,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Println(msg("dev.running", addr))
+	fmt.Println(msg("build.project", projectPath))
+	return httpServer.ListenAndServe()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/project"
+)
+
+var initForce bool
+
+func newInitCmd() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:   "init <dir>",
+		Short: "Scaffold an editable watchface project from a template",
+		Long: `Write a template's files, a watchface.yaml and a .gitignore into a directory.
+
+The project builds the files with the custom template, so a built-in face
+becomes the starting point for your own.
+
+Examples:
+  watchface-builder init my-face --template analog
+  cd my-face && watchface-builder build`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runInit,
+	}
+
+	initCmd.Flags().StringVarP(&name, "name", "n", "", "Watchface name (default directory name)")
+	initCmd.Flags().StringVarP(&version, "version", "v", "1.0.0", "Version number")
+	initCmd.Flags().StringVarP(&author, "author", "a", "Anonymous", "Author name")
+	initCmd.Flags().StringVarP(&description, "description", "d", "", "Watchface description")
+	initCmd.Flags().StringVarP(&template, "template", "t", "simple", "Template to start from")
+	initCmd.Flags().StringVar(&tags, "tags", "", "Tags, comma-separated")
+	initCmd.Flags().StringSliceVar(&devices, "device", nil, "Target device profile to lay the template out for (repeatable)")
+	initCmd.Flags().StringSliceVar(&localeTags, "locale", nil, "Locale of the face (repeatable, the first one is the default)")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Write into a non-empty directory")
+
+	return initCmd
+}
+
+func runInit(_ *cobra.Command, args []string) error {
+	dir := args[0]
+
+	tmpl, ok := builder.DefaultRegistry().Lookup(template)
+	if !ok {
+		return fmt.Errorf("invalid template: %s", template)
+	}
+
+	if name == "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		name = filepath.Base(absDir)
+	}
+
+	options := builder.BuildOptions{
+		Name:        name,
+		Version:     version,
+		Author:      author,
+		Description: description,
+		Tags:        parseTags(tags),
+		Template:    template,
+		Devices:     devices,
+		Locales:     localeTags,
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if _, err := project.Init(dir, tmpl, options, initForce); err != nil {
+		return err
+	}
+
+	fmt.Println(msg("init.created", dir, template))
+	fmt.Println()
+	fmt.Println(msg("next_steps"))
+	fmt.Println(msg("init.next_edit", dir))
+	fmt.Println(msg("init.next_build", dir))
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+var inspectJSON bool
+
+func newInspectCmd() *cobra.Command {
+	inspectCmd := &cobra.Command{
+		Use:   "inspect <zip>",
+		Short: "Print the manifest, file tree and hashes of a package",
+		Long: `Inspect a built watchface package.
+
+Prints the parsed manifest, every entry with its size, compression method and
+SHA256, the SHA256 of the package itself and the preview image dimensions.
+
+Examples:
+  watchface-builder inspect My_Watchface_v1.0.0_20250121_100000.zip
+  watchface-builder inspect package.zip --json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runInspect,
+	}
+
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "Print the result as JSON")
+
+	return inspectCmd
+}
+
+func runInspect(_ *cobra.Command, args []string) error {
+	info, err := builder.InspectPackage(args[0])
+	if err != nil {
+		return err
+	}
+
+	if inspectJSON {
+		infoJSON, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Println(string(infoJSON))
+		return nil
+	}
+
+	printPackageInfo(info)
+	return nil
+}
+
+func printPackageInfo(info *builder.PackageInfo) {
+	fmt.Println(msg("inspect.package"))
+	fmt.Println(msg("inspect.path", info.Path))
+	fmt.Println(msg("inspect.size", float64(info.Size)/1024))
+	fmt.Println(msg("inspect.sha256", info.SHA256))
+	if info.Preview != nil {
+		fmt.Println(msg("inspect.preview", info.Preview.File, info.Preview.Width, info.Preview.Height))
+	}
+	fmt.Println()
+
+	if info.Manifest != nil {
+		fmt.Println(msg("inspect.manifest"))
+		manifestJSON, _ := json.MarshalIndent(info.Manifest, "  ", "  ")
+		fmt.Println("  " + string(manifestJSON))
+		fmt.Println()
+	}
+
+	fmt.Println(msg("inspect.files"))
+	printedDirs := map[string]bool{}
+	for _, entry := range info.Entries {
+		// Print each parent directory once before its first file
+		dir := path.Dir(entry.Name)
+		var parents []string
+		for ; dir != "." && !printedDirs[dir]; dir = path.Dir(dir) {
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			printedDirs[parents[i]] = true
+			depth := strings.Count(parents[i], "/")
+			fmt.Printf("  %s%s/\n", strings.Repeat("  ", depth), path.Base(parents[i]))
+		}
+
+		depth := strings.Count(entry.Name, "/")
+		label := strings.Repeat("  ", depth) + path.Base(entry.Name)
+		fmt.Printf("  %-28s %10d B  %-7s  %s\n", label, entry.Size, entry.Method, entry.SHA256)
+	}
+	fmt.Println()
+}
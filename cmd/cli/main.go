@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	_ "time/tzdata" // Timezone complications work without a system zone database
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/project"
 )
 
 var (
@@ -28,16 +32,99 @@ var (
 	customHTMLFile string
 	customCSSFile  string
 	customJSFile   string
+	templateDirs   []string
+	sourceDir      string
+	includes       []string
+	excludes       []string
+	reproducible   bool
+	signingKey     string
+	devices        []string
+	listDevices    bool
+	storeImages    bool
+	previewTime    string
+	permissions    []string
+	complications  []string
+	localeTags     []string
+	themeFile      string
+	background     string
+	foreground     string
+	accent         string
+	fontFamily     string
+	markerStyle    string
+	hourHand       string
+	minuteHand     string
+	secondHand     string
+	minifyHTML     bool
+	minifyCSS      bool
+	minifyJS       bool
+	optimizeImages bool
+	inline         bool
+	fontFile       string
 )
 
+// addBuildFlags registers the flags shared by every command that builds a package
+func addBuildFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&name, "name", "n", "", "Watchface name (required)")
+	flags.StringVarP(&version, "version", "v", "1.0.0", "Version number")
+	flags.StringVarP(&author, "author", "a", "Anonymous", "Author name")
+	flags.StringVarP(&description, "description", "d", "", "Watchface description")
+	flags.StringVarP(&template, "template", "t", "simple",
+		"Template type: "+strings.Join(builder.DefaultRegistry().IDs(), ", "))
+	flags.StringVar(&tags, "tags", "", "Tags, comma-separated")
+	flags.StringSliceVar(&devices, "device", nil, "Target device profile (repeatable, the first one is laid out for)")
+	flags.StringSliceVar(&permissions, "permission", nil,
+		"Watch data the face reads through watchface.js (repeatable): "+strings.Join(builder.Permissions(), ", "))
+	flags.StringArrayVar(&complications, "complication", nil,
+		"Complication as slot=provider[:timezone] (repeatable), e.g. top=date, bottom=timezone:Europe/London; "+
+			"timezone offsets are built in through 2035 and read from the watch's Intl support after")
+	flags.StringSliceVar(&localeTags, "locale", nil,
+		"Locale of weekday names, date order and clock (repeatable, the first one is the default): "+
+			strings.Join(builder.Locales(), ", "))
+	flags.StringVar(&themeFile, "theme-file", "", "Theme file (YAML or JSON), overridden by the individual theme flags")
+	flags.StringVar(&background, "background", "", "Background gradient as from,to colours, e.g. \"#000000,#1e1e3c\"")
+	flags.StringVar(&foreground, "foreground", "", "Text and dial colour")
+	flags.StringVar(&accent, "accent", "", "Accent colour of the second hand and secondary text")
+	flags.StringVar(&fontFamily, "font-family", "", "CSS font family list, e.g. \"'Fira Sans', sans-serif\"")
+	flags.StringVar(&fontFile, "font", "", "TrueType font to bundle and use, subset to the characters the face shows")
+	flags.StringVar(&markerStyle, "markers", "",
+		"Hour markers of the analog face: "+strings.Join(builder.MarkerStyles(), ", "))
+	flags.StringVar(&hourHand, "hour-hand", "", "Hour hand as width,length[,color], length relative to the dial radius")
+	flags.StringVar(&minuteHand, "minute-hand", "", "Minute hand as width,length[,color]")
+	flags.StringVar(&secondHand, "second-hand", "", "Second hand as width,length[,color]")
+	flags.StringVarP(&output, "output", "o", ".", "Output directory")
+	flags.BoolVar(&noPreview, "no-preview", false, "Do not generate preview image")
+	flags.BoolVar(&storeImages, "store-images", false, "Also generate store listing images (thumbnail and banner)")
+	flags.StringVar(&previewTime, "preview-time", "", "Time shown in previews, HH:MM[:SS] or RFC 3339 (default 10:08:36)")
+	flags.BoolVar(&minifyHTML, "minify-html", false, "Minify HTML files, including inline styles and scripts")
+	flags.BoolVar(&minifyCSS, "minify-css", false, "Minify CSS files")
+	flags.BoolVar(&minifyJS, "minify-js", false, "Minify JS files")
+	flags.BoolVar(&optimizeImages, "optimize-images", false, "Recompress PNG files losslessly")
+	flags.BoolVar(&inline, "inline", false,
+		"Fold stylesheets, scripts, small images and fonts into index.html")
+	flags.StringVar(&customHTML, "custom-html", "", "Custom HTML content")
+	flags.StringVar(&customCSS, "custom-css", "", "Custom CSS content")
+	flags.StringVar(&customJS, "custom-js", "", "Custom JS content")
+	flags.StringVar(&customHTMLFile, "custom-html-file", "", "Custom HTML file path")
+	flags.StringVar(&customCSSFile, "custom-css-file", "", "Custom CSS file path")
+	flags.StringVar(&customJSFile, "custom-js-file", "", "Custom JS file path")
+	flags.StringVar(&sourceDir, "source", "", "Package this directory tree instead of a template")
+	flags.StringSliceVar(&includes, "include", nil, "Glob of source files to include (repeatable, supports **)")
+	flags.StringSliceVar(&excludes, "exclude", nil, "Glob of source files to exclude (repeatable, supports **)")
+	flags.BoolVar(&reproducible, "reproducible", false,
+		"Produce byte-identical packages (timestamps from SOURCE_DATE_EPOCH)")
+	flags.StringVar(&signingKey, "sign-key", "", "Sign the package with this Ed25519 private key file")
+}
+
 func main() {
+	if err := selectLanguage(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	rootCmd := &cobra.Command{
 		Use:   "watchface-builder",
 		Short: "A tool to quickly generate H5 watchface packages",
-		Long: `Watchface Builder - 表盘包创建器
-
-A lazy-friendly tool to quickly generate H5 watchface packages for WebView-based smartwatches.
-为 WebView 套壳智能手表快速生成 H5 表盘包的懒人工具。
+		Long: msg("cli.long") + `
 
 Examples:
   # Interactive mode (recommended for beginners)
@@ -50,27 +137,78 @@ Examples:
   watchface-builder -name "My Watchface" -template digital \
     -version 1.0.0 -author "Your Name" -description "A cool watchface"
 
+  # Lay out for a round watch
+  watchface-builder -name "My Watchface" -template analog --device round-454
+
+  # Restyle a built-in template
+  watchface-builder -name "My Watchface" -template analog \
+    --background "#1e1e3c,#0a0a1e" --foreground "#eeeeee" --markers numerals
+
+  # Localise weekday names, date order and the 12/24-hour clock, picking
+  # the locale matching the watch's language at runtime
+  watchface-builder -name "My Watchface" -template digital --locale en-US --locale de-DE
+
+  # Bundle a font, subset to the glyphs the face shows
+  watchface-builder -name "My Watchface" -template digital --font ./fonts/DSEG7.ttf
+
+  # Shrink the package for watches with little storage
+  watchface-builder -name "My Watchface" --minify-html --minify-css --minify-js --optimize-images
+
+  # Ship a single self-contained index.html
+  watchface-builder -name "My Watchface" --source ./src --inline
+
+  # Package a directory tree with images, fonts and nested folders
+  watchface-builder -name "My Watchface" --source ./src --exclude "**/*.psd"
+
   # List available templates
-  watchface-builder -list`,
-		Run: runBuild,
-	}
-
-	rootCmd.Flags().StringVarP(&name, "name", "n", "", "Watchface name (required)")
-	rootCmd.Flags().StringVarP(&version, "version", "v", "1.0.0", "Version number")
-	rootCmd.Flags().StringVarP(&author, "author", "a", "Anonymous", "Author name")
-	rootCmd.Flags().StringVarP(&description, "description", "d", "", "Watchface description")
-	rootCmd.Flags().StringVarP(&template, "template", "t", "simple", "Template type: simple, analog, digital, custom")
-	rootCmd.Flags().StringVar(&tags, "tags", "", "Tags, comma-separated")
-	rootCmd.Flags().StringVarP(&output, "output", "o", ".", "Output directory")
-	rootCmd.Flags().BoolVar(&noPreview, "no-preview", false, "Do not generate preview image")
+  watchface-builder -list
+
+  # Scaffold an editable project from a template
+  watchface-builder init my-face --template analog
+
+  # Build the project described by watchface.yaml in the current directory
+  watchface-builder build .
+
+  # Preview the project in a watch simulator that reloads on every save
+  watchface-builder dev .
+
+  # Check a package against the specification
+  watchface-builder validate My_Watchface_v1.0.0_20250121_100000.zip
+
+  # Print the manifest, file tree and hashes of a package
+  watchface-builder inspect My_Watchface_v1.0.0_20250121_100000.zip
+
+  # Sign a package and verify it on the distribution side
+  watchface-builder keygen --out watchface
+  watchface-builder -name "My Watchface" --sign-key watchface.key
+  watchface-builder verify My_Watchface_v1.0.0_20250121_100000.zip --key watchface.pub
+
+  # Run the REST API server
+  watchface-builder serve --port 8080`,
+		SilenceErrors:     true,
+		PersistentPreRunE: loadTemplateDirs,
+		Run:               runBuild,
+	}
+
+	addBuildFlags(rootCmd.Flags())
 	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive mode")
 	rootCmd.Flags().BoolVarP(&listTemplates, "list", "l", false, "List available templates")
-	rootCmd.Flags().StringVar(&customHTML, "custom-html", "", "Custom HTML content")
-	rootCmd.Flags().StringVar(&customCSS, "custom-css", "", "Custom CSS content")
-	rootCmd.Flags().StringVar(&customJS, "custom-js", "", "Custom JS content")
-	rootCmd.Flags().StringVar(&customHTMLFile, "custom-html-file", "", "Custom HTML file path")
-	rootCmd.Flags().StringVar(&customCSSFile, "custom-css-file", "", "Custom CSS file path")
-	rootCmd.Flags().StringVar(&customJSFile, "custom-js-file", "", "Custom JS file path")
+	rootCmd.Flags().BoolVar(&listDevices, "list-devices", false, "List available device profiles")
+
+	rootCmd.PersistentFlags().StringSliceVar(&templateDirs, "template-dir", nil,
+		"Directory containing template directories (repeatable)")
+	rootCmd.PersistentFlags().String("lang", "",
+		"Language of the output: "+strings.Join(languages(), ", ")+" (default from LANG)")
+
+	rootCmd.AddCommand(newBuildCmd())
+	rootCmd.AddCommand(newDevCmd())
+	rootCmd.AddCommand(newInitCmd())
+	rootCmd.AddCommand(newInspectCmd())
+	rootCmd.AddCommand(newKeygenCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newSignCmd())
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newVerifyCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -78,6 +216,27 @@ Examples:
 	}
 }
 
+// loadTemplateDirs registers on-disk templates from the user config directory and
+// --template-dir, warning about those skipped because their ID is already taken
+func loadTemplateDirs(_ *cobra.Command, _ []string) error {
+	var paths []string
+	if userDir, err := builder.UserTemplateDir(); err == nil {
+		if info, err := os.Stat(userDir); err == nil && info.IsDir() {
+			paths = append(paths, userDir)
+		}
+	}
+	paths = append(paths, templateDirs...)
+
+	skipped, err := builder.DefaultRegistry().LoadTemplateDirs(paths...)
+	if err != nil {
+		return err
+	}
+	for _, s := range skipped {
+		_, _ = fmt.Fprintln(os.Stderr, msg("warning.template_skipped", s.Template.Dir(), s.Err))
+	}
+	return nil
+}
+
 func runBuild(cmd *cobra.Command, _ []string) {
 	printBanner()
 
@@ -87,6 +246,12 @@ func runBuild(cmd *cobra.Command, _ []string) {
 		return
 	}
 
+	// List devices mode
+	if listDevices {
+		printDeviceList()
+		return
+	}
+
 	// Interactive mode
 	if interactive {
 		runInteractive()
@@ -95,14 +260,14 @@ func runBuild(cmd *cobra.Command, _ []string) {
 
 	// Validate required parameters
 	if name == "" {
-		fmt.Println("❌ Error: Watchface name is required")
+		fmt.Println(msg("error.name_required"))
 		fmt.Println()
-		fmt.Println("Usage:")
+		fmt.Println(msg("usage"))
 		_ = cmd.Help()
 		fmt.Println()
-		fmt.Println("Quick start:")
+		fmt.Println(msg("quick_start"))
 		fmt.Println("  watchface-builder -name \"My Watchface\"")
-		fmt.Println("  watchface-builder -i  # Interactive mode")
+		fmt.Println("  watchface-builder -i  # " + msg("quick_start.interactive"))
 		fmt.Println()
 		os.Exit(1)
 	}
@@ -111,8 +276,8 @@ func runBuild(cmd *cobra.Command, _ []string) {
 	buildWatchface()
 }
 
-func buildWatchface() {
-	// Parse tags
+// parseTags splits a comma-separated tag list
+func parseTags(tags string) []string {
 	var tagList []string
 	if tags != "" {
 		tagList = strings.Split(tags, ",")
@@ -120,12 +285,99 @@ func buildWatchface() {
 			tagList[i] = strings.TrimSpace(tagList[i])
 		}
 	}
+	return tagList
+}
+
+// parseComplications parses the --complication flags
+func parseComplications() ([]builder.Complication, error) {
+	var parsed []builder.Complication
+	for _, spec := range complications {
+		c, err := builder.ParseComplication(spec)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, c)
+	}
+	return parsed, nil
+}
+
+// parseThemeFlags parses the individual theme flags; unset flags leave the field empty
+func parseThemeFlags() (builder.Theme, error) {
+	theme := builder.Theme{
+		Foreground: foreground,
+		Accent:     accent,
+		FontFamily: fontFamily,
+		Markers:    markerStyle,
+	}
+	if background != "" {
+		gradient, err := builder.ParseBackground(background)
+		if err != nil {
+			return theme, err
+		}
+		theme.Background = gradient
+	}
+
+	hands := []struct {
+		spec   string
+		target *builder.HandStyle
+	}{
+		{hourHand, &theme.HourHand},
+		{minuteHand, &theme.MinuteHand},
+		{secondHand, &theme.SecondHand},
+	}
+	for _, hand := range hands {
+		if hand.spec == "" {
+			continue
+		}
+		style, err := builder.ParseHandStyle(hand.spec)
+		if err != nil {
+			return theme, err
+		}
+		*hand.target = style
+	}
+	return theme, nil
+}
+
+// parseTheme loads --theme-file and applies the individual theme flags on top
+func parseTheme() (builder.Theme, error) {
+	overrides, err := parseThemeFlags()
+	if err != nil || themeFile == "" {
+		return overrides, err
+	}
+	theme, err := project.LoadTheme(themeFile)
+	if err != nil {
+		return theme, err
+	}
+	return theme.Merge(overrides), nil
+}
+
+func buildWatchface() {
+	// Parse tags
+	tagList := parseTags(tags)
+	complicationList, err := parseComplications()
+	if err != nil {
+		fmt.Println(msg("error", err))
+		os.Exit(1)
+	}
+	theme, err := parseTheme()
+	if err != nil {
+		fmt.Println(msg("error", err))
+		os.Exit(1)
+	}
 
 	// Read custom files if specified
+	var fontData []byte
+	if fontFile != "" {
+		fontData, err = os.ReadFile(fontFile)
+		if err != nil {
+			fmt.Println(msg("error.read_font", err))
+			os.Exit(1)
+		}
+	}
 	if customHTMLFile != "" {
 		content, err := os.ReadFile(customHTMLFile)
 		if err != nil {
-			fmt.Printf("❌ Failed to read custom HTML file: %v\n", err)
+			fmt.Println(msg("error.read_html", err))
 			os.Exit(1)
 		}
 		customHTML = string(content)
@@ -133,7 +385,7 @@ func buildWatchface() {
 	if customCSSFile != "" {
 		content, err := os.ReadFile(customCSSFile)
 		if err != nil {
-			fmt.Printf("❌ Failed to read custom CSS file: %v\n", err)
+			fmt.Println(msg("error.read_css", err))
 			os.Exit(1)
 		}
 		customCSS = string(content)
@@ -141,7 +393,7 @@ func buildWatchface() {
 	if customJSFile != "" {
 		content, err := os.ReadFile(customJSFile)
 		if err != nil {
-			fmt.Printf("❌ Failed to read custom JS file: %v\n", err)
+			fmt.Println(msg("error.read_js", err))
 			os.Exit(1)
 		}
 		customJS = string(content)
@@ -154,24 +406,62 @@ func buildWatchface() {
 		Author:          author,
 		Description:     description,
 		Template:        template,
+		Devices:         devices,
+		Permissions:     permissions,
+		Complications:   complicationList,
+		Locales:         localeTags,
+		Theme:           theme,
+		Font:            fontData,
 		Tags:            tagList,
 		OutputPath:      output,
 		GeneratePreview: !noPreview,
+		StoreImages:     storeImages,
+		MinifyHTML:      minifyHTML,
+		MinifyCSS:       minifyCSS,
+		MinifyJS:        minifyJS,
+		OptimizeImages:  optimizeImages,
+		Inline:          inline,
 		CustomHTML:      customHTML,
 		CustomCSS:       customCSS,
 		CustomJS:        customJS,
+		SourceDir:       sourceDir,
+		Include:         includes,
+		Exclude:         excludes,
+		Reproducible:    reproducible,
+	}
+
+	executeBuild(options)
+}
+
+// executeBuild runs the builder and prints the result, exiting on failure
+func executeBuild(options builder.BuildOptions) {
+	if previewTime != "" {
+		at, err := builder.ParsePreviewTime(previewTime)
+		if err != nil {
+			fmt.Println(msg("error", err))
+			os.Exit(1)
+		}
+		options.PreviewTime = at
+	}
+	if signingKey != "" {
+		key, err := builder.LoadPrivateKey(signingKey)
+		if err != nil {
+			fmt.Println(msg("error.load_signing_key", err))
+			os.Exit(1)
+		}
+		options.SigningKey = key
 	}
 
 	// Create builder
 	b := builder.NewBuilder()
 
-	fmt.Println("🔨 Building watchface package...")
+	fmt.Println(msg("build.building"))
 	fmt.Println()
 
 	// Execute build
 	result, err := b.Build(options)
 	if err != nil {
-		fmt.Printf("❌ Build failed: %v\n", err)
+		fmt.Println(msg("build.failed", err))
 		os.Exit(1)
 	}
 
@@ -180,22 +470,22 @@ func buildWatchface() {
 }
 
 func runInteractive() {
-	fmt.Println("🎯 Interactive Mode")
+	fmt.Println(msg("interactive.title"))
 	fmt.Println()
 
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// Name
-	fmt.Print("Watchface name (required): ")
+	fmt.Print(msg("interactive.name"))
 	scanner.Scan()
 	name = scanner.Text()
 	if name == "" {
-		fmt.Println("❌ Name is required")
+		fmt.Println(msg("interactive.name_required"))
 		os.Exit(1)
 	}
 
 	// Version
-	fmt.Print("Version [1.0.0]: ")
+	fmt.Print(msg("interactive.version"))
 	scanner.Scan()
 	version = scanner.Text()
 	if version == "" {
@@ -203,7 +493,7 @@ func runInteractive() {
 	}
 
 	// Author
-	fmt.Print("Author [Anonymous]: ")
+	fmt.Print(msg("interactive.author"))
 	scanner.Scan()
 	author = scanner.Text()
 	if author == "" {
@@ -211,120 +501,137 @@ func runInteractive() {
 	}
 
 	// Description
-	fmt.Print("Description (optional): ")
+	fmt.Print(msg("interactive.description"))
 	scanner.Scan()
 	description = scanner.Text()
 
 	// Template selection
+	templates := builder.DefaultRegistry().Templates()
 	fmt.Println()
-	fmt.Println("Select template:")
-	fmt.Println("  1. Simple   - Minimalist digital clock")
-	fmt.Println("  2. Analog   - Classic clock with hands")
-	fmt.Println("  3. Digital  - Tech-style digital clock")
-	fmt.Println("  4. Custom   - Custom HTML/CSS/JS")
-	fmt.Print("Enter option [1]: ")
+	fmt.Println(msg("interactive.select_template"))
+	for i, t := range templates {
+		fmt.Printf("  %d. %-8s - %s\n", i+1, t.ID(), templateDescription(t))
+	}
+	fmt.Print(msg("interactive.option"))
 	scanner.Scan()
-	choice := scanner.Text()
-	switch choice {
-	case "2":
-		template = "analog"
-	case "3":
-		template = "digital"
-	case "4":
-		template = "custom"
+	template = templates[0].ID()
+	if choice, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil && choice >= 1 && choice <= len(templates) {
+		template = templates[choice-1].ID()
+	}
+	if template == "custom" {
 		fmt.Println()
-		fmt.Println("⚠️  Custom template requires HTML content")
-		fmt.Print("HTML file path: ")
+		fmt.Println(msg("interactive.custom_needs_html"))
+		fmt.Print(msg("interactive.html_file"))
 		scanner.Scan()
 		customHTMLFile = scanner.Text()
-		fmt.Print("CSS file path (optional): ")
+		fmt.Print(msg("interactive.css_file"))
 		scanner.Scan()
 		customCSSFile = scanner.Text()
-		fmt.Print("JS file path (optional): ")
+		fmt.Print(msg("interactive.js_file"))
 		scanner.Scan()
 		customJSFile = scanner.Text()
-	default:
-		template = "simple"
 	}
 
 	// Tags
-	fmt.Print("Tags (comma-separated, optional): ")
+	fmt.Print(msg("interactive.tags"))
 	scanner.Scan()
 	tags = scanner.Text()
 
 	// Preview
-	fmt.Print("Generate preview image? [Y/n]: ")
+	fmt.Print(msg("interactive.preview"))
 	scanner.Scan()
 	previewChoice := strings.ToLower(scanner.Text())
 	noPreview = previewChoice == "n"
 
 	fmt.Println()
-	fmt.Println("🔨 Building...")
+	fmt.Println(msg("interactive.building"))
 	fmt.Println()
 
 	// Build
 	buildWatchface()
 }
 
+// bannerWidth is the number of columns inside the banner's box
+const bannerWidth = 55
+
 func printBanner() {
-	banner := `
-╔═══════════════════════════════════════════════════════╗
-║   Watchface Builder - 表盘包创建器                    ║
-║   Quickly generate H5 watchface packages              ║
-║   快速生成 H5 表盘包                                  ║
-╚═══════════════════════════════════════════════════════╝`
-	fmt.Println(banner)
+	fmt.Println()
+	fmt.Println("╔" + strings.Repeat("═", bannerWidth) + "╗")
+	for _, line := range []string{msg("banner.title"), msg("banner.tagline")} {
+		padding := max(0, bannerWidth-3-displayWidth(line))
+		fmt.Println("║   " + line + strings.Repeat(" ", padding) + "║")
+	}
+	fmt.Println("╚" + strings.Repeat("═", bannerWidth) + "╝")
 }
 
 func printTemplateList() {
-	fmt.Println("📋 Available Templates:")
-	fmt.Println()
-	fmt.Println("  1. simple")
-	fmt.Println("     └─ Minimalist digital clock with gradient background")
-	fmt.Println("     └─ 简洁的数字时钟，带渐变背景")
+	fmt.Println(msg("templates.title"))
 	fmt.Println()
-	fmt.Println("  2. analog")
-	fmt.Println("     └─ Classic analog clock with Canvas rendering")
-	fmt.Println("     └─ 经典指针时钟，Canvas 绘制")
+	for i, t := range builder.DefaultRegistry().Templates() {
+		fmt.Printf("  %d. %s\n", i+1, t.ID())
+		fmt.Printf("     └─ %s\n", templateName(t))
+		if description := templateDescription(t); description != "" {
+			fmt.Printf("     └─ %s\n", description)
+		}
+		if dirTemplate, ok := t.(*builder.DirTemplate); ok {
+			fmt.Printf("     └─ %s\n", dirTemplate.Dir())
+		}
+		fmt.Println()
+	}
+	fmt.Println(msg("usage_example"))
+	fmt.Println("  watchface-builder -name \"My Watchface\" -template analog")
 	fmt.Println()
-	fmt.Println("  3. digital")
-	fmt.Println("     └─ Tech-style digital clock with neon effects")
-	fmt.Println("     └─ 科技感数字时钟，霓虹灯效果")
+}
+
+func printDeviceList() {
+	fmt.Println(msg("devices.title"))
 	fmt.Println()
-	fmt.Println("  4. custom")
-	fmt.Println("     └─ Fully customizable with your own HTML/CSS/JS")
-	fmt.Println("     └─ 使用自定义 HTML/CSS/JS 完全自定义")
+	for _, device := range builder.DeviceProfiles() {
+		fmt.Printf("  %-14s %s %4dx%-4d %-6s DPR %.1f  %s/%s\n",
+			device.ID, padRight(deviceName(device), 28), device.Width, device.Height, device.Shape, device.DPR, device.JS, device.CSS)
+	}
 	fmt.Println()
-	fmt.Println("Usage example:")
-	fmt.Println("  watchface-builder -name \"My Watchface\" -template analog")
+	fmt.Println(msg("usage_example"))
+	fmt.Println("  watchface-builder -name \"My Watchface\" -template analog --device round-454")
 	fmt.Println()
 }
 
 func printResult(result *builder.BuildResult) {
-	fmt.Println("✅ Build successful!")
+	fmt.Println(msg("result.success"))
 	fmt.Println()
-	fmt.Println("📦 Output:")
-	fmt.Printf("  File path: %s\n", result.ZipPath)
-	fmt.Printf("  File size: %.2f KB\n", float64(result.Size)/1024)
-	fmt.Printf("  File hash: %s\n", result.FileHash)
-	fmt.Printf("  File count: %d\n", result.FileCount)
+	fmt.Println(msg("result.output"))
+	fmt.Println(msg("result.path", result.ZipPath))
+	fmt.Println(msg("result.size", float64(result.Size)/1024))
+	fmt.Println(msg("result.hash", result.FileHash))
+	fmt.Println(msg("result.count", result.FileCount))
+	if result.Signature != "" {
+		fmt.Println(msg("result.signature", result.Signature))
+	}
 	fmt.Println()
-	fmt.Println("📋 Files included:")
+	fmt.Println(msg("result.files"))
 	for _, file := range result.Files {
 		fmt.Printf("  ✓ %s\n", file)
 	}
 	fmt.Println()
-	fmt.Println("📄 Manifest:")
+	if savings, ok := result.Metadata["asset_savings"].([]builder.AssetSavings); ok && len(savings) > 0 {
+		fmt.Println(msg("result.optimized"))
+		for _, s := range savings {
+			fmt.Println(msg("result.saving", s.File, s.Before, s.After,
+				float64(s.Saved())*100/float64(s.Before)))
+		}
+		fmt.Println()
+	}
+	fmt.Println(msg("result.manifest"))
 	var manifest map[string]interface{}
 	if err := json.Unmarshal([]byte(result.Manifest), &manifest); err == nil {
 		manifestJSON, _ := json.MarshalIndent(manifest, "  ", "  ")
 		fmt.Println(string(manifestJSON))
 	}
 	fmt.Println()
-	fmt.Println("🎉 Done! You can now upload and validate this watchface package.")
+	fmt.Println(msg("result.done"))
 	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  1. Test locally: unzip", result.ZipPath)
-	fmt.Println("  2. Validate: Upload to validation service")
+	fmt.Println(msg("next_steps"))
+	fmt.Println(msg("result.next_test", result.ZipPath))
+	fmt.Println(msg("result.next_validate", result.ZipPath))
 	fmt.Println()
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+// The CLI's own messages come from a message catalogue per language,
+// messages/<tag>.json, mapping message keys to fmt formats. Keys missing from a
+// catalogue fall back to English. Help texts of commands and flags, other than
+// the root command's introduction, are English only, as is text that comes from
+// the builder: validation issues and the details of errors.
+
+//go:embed messages/*.json
+var messageFiles embed.FS
+
+// defaultLanguage is the language used when LANG and --lang name no catalogue
+const defaultLanguage = "en"
+
+var (
+	catalogues = loadCatalogues()
+	messages   = catalogues[defaultLanguage]
+)
+
+// loadCatalogues reads the embedded catalogues keyed by language tag
+func loadCatalogues() map[string]map[string]string {
+	entries, err := messageFiles.ReadDir("messages")
+	if err != nil {
+		panic(err)
+	}
+	loaded := map[string]map[string]string{}
+	for _, entry := range entries {
+		data, err := messageFiles.ReadFile(path.Join("messages", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalogue map[string]string
+		if err := json.Unmarshal(data, &catalogue); err != nil {
+			panic(fmt.Sprintf("invalid message catalogue %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = catalogue
+	}
+	return loaded
+}
+
+// languages returns the tags of the available catalogues, sorted
+func languages() []string {
+	tags := make([]string, 0, len(catalogues))
+	for tag := range catalogues {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// matchLanguage returns the catalogue tag for a locale such as zh-CN, zh_CN.UTF-8
+// or zh, matching the whole tag first and then the language alone
+func matchLanguage(locale string) (string, bool) {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if locale == "" {
+		return "", false
+	}
+	for _, tag := range languages() {
+		if strings.EqualFold(tag, locale) {
+			return tag, true
+		}
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	for _, tag := range languages() {
+		if prefix, _, _ := strings.Cut(tag, "-"); strings.EqualFold(prefix, language) {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// selectLanguage picks the catalogue from --lang in args, or else from the
+// LC_ALL, LC_MESSAGES and LANG environment variables. The flag is read before
+// cobra parses the command line so that help texts are translated too.
+func selectLanguage(args []string) error {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		value, ok := strings.CutPrefix(arg, "--lang=")
+		if !ok && arg == "--lang" && i+1 < len(args) {
+			value, ok = args[i+1], true
+		}
+		if !ok {
+			continue
+		}
+		tag, found := matchLanguage(value)
+		if !found {
+			return fmt.Errorf("unsupported language %q (expected one of %s)", value, strings.Join(languages(), ", "))
+		}
+		messages = catalogues[tag]
+		return nil
+	}
+
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(variable); value != "" {
+			if tag, ok := matchLanguage(value); ok {
+				messages = catalogues[tag]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// msg returns the message for key in the selected language, formatted with args
+func msg(key string, args ...interface{}) string {
+	format, ok := messages[key]
+	if !ok {
+		if format, ok = catalogues[defaultLanguage][key]; !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// translate returns the message for key in the selected language or English,
+// or fallback if neither catalogue has it
+func translate(key, fallback string) string {
+	if text, ok := messages[key]; ok {
+		return text
+	}
+	if text, ok := catalogues[defaultLanguage][key]; ok {
+		return text
+	}
+	return fallback
+}
+
+// templateName returns the translated name of a built-in template. Templates
+// loaded from directories keep the name of their template.json.
+func templateName(t builder.Template) string {
+	if _, ok := t.(*builder.DirTemplate); ok {
+		return t.Name()
+	}
+	return translate("template."+t.ID()+".name", t.Name())
+}
+
+// templateDescription returns the translated description of a built-in template
+func templateDescription(t builder.Template) string {
+	if _, ok := t.(*builder.DirTemplate); ok {
+		return t.Description()
+	}
+	return translate("template."+t.ID()+".description", t.Description())
+}
+
+// deviceName returns the translated name of a device profile
+func deviceName(device builder.DeviceProfile) string {
+	return translate("device."+device.ID+".name", device.Name)
+}
+
+// padRight pads text with spaces to width terminal columns
+func padRight(text string, width int) string {
+	if n := width - displayWidth(text); n > 0 {
+		return text + strings.Repeat(" ", n)
+	}
+	return text
+}
+
+// displayWidth returns the number of terminal columns text takes, counting
+// East Asian wide characters twice
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana),
+			r >= 0x3000 && r <= 0x303f, r >= 0xff01 && r <= 0xff60:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+func TestCataloguesHaveSameKeys(t *testing.T) {
+	for tag, catalogue := range catalogues {
+		for key := range catalogues[defaultLanguage] {
+			if _, ok := catalogue[key]; !ok {
+				t.Errorf("%s is missing %s", tag, key)
+			}
+		}
+		for key := range catalogue {
+			if _, ok := catalogues[defaultLanguage][key]; !ok {
+				t.Errorf("%s has %s, which %s lacks", tag, key, defaultLanguage)
+			}
+		}
+	}
+}
+
+func TestListingsAreTranslated(t *testing.T) {
+	defer func(selected map[string]string) {
+		messages = selected
+	}(messages)
+	messages = catalogues["zh-CN"]
+
+	listing := captureStdout(t, func() {
+		printTemplateList()
+		printDeviceList()
+	})
+
+	for _, tmpl := range builder.DefaultRegistry().Templates() {
+		for _, key := range []string{"template." + tmpl.ID() + ".name", "template." + tmpl.ID() + ".description"} {
+			if _, ok := messages[key]; !ok {
+				t.Errorf("zh-CN is missing %s", key)
+			}
+		}
+		for _, english := range []string{tmpl.Name(), tmpl.Description()} {
+			if strings.Contains(listing, english) {
+				t.Errorf("listing contains %q", english)
+			}
+		}
+		if !strings.Contains(listing, templateName(tmpl)) {
+			t.Errorf("listing lacks %q", templateName(tmpl))
+		}
+	}
+	for _, device := range builder.DeviceProfiles() {
+		if _, ok := messages["device."+device.ID+".name"]; !ok {
+			t.Errorf("zh-CN is missing device.%s.name", device.ID)
+		}
+		if strings.Contains(listing, device.Name) {
+			t.Errorf("listing contains %q", device.Name)
+		}
+		if !strings.Contains(listing, deviceName(device)) {
+			t.Errorf("listing lacks %q", deviceName(device))
+		}
+	}
+}
+
+func TestDirTemplatesKeepTheirNames(t *testing.T) {
+	dir := t.TempDir()
+	// Even with the ID of a built-in template, the catalogue is not consulted
+	descriptor := `{"id": "simple", "name": "My Face", "description": "From a directory"}`
+	if err := os.WriteFile(filepath.Join(dir, "template.json"), []byte(descriptor), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html.tmpl"), []byte("<html></html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := builder.LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(selected map[string]string) {
+		messages = selected
+	}(messages)
+	messages = catalogues["zh-CN"]
+	if name := templateName(tmpl); name != "My Face" {
+		t.Errorf("templateName() = %q, want %q", name, "My Face")
+	}
+	if description := templateDescription(tmpl); description != "From a directory" {
+		t.Errorf("templateDescription() = %q, want %q", description, "From a directory")
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	done := make(chan string)
+	go func() {
+		output, _ := io.ReadAll(reader)
+		done <- string(output)
+	}()
+	fn()
+	os.Stdout = stdout
+	_ = writer.Close()
+	return <-done
+}
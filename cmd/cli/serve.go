@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/server"
+)
+
+var (
+	serveHost        string
+	servePort        int
+	serveArtifactDir string
+	serveAuthToken   string
+	serveCORSOrigins string
+)
+
+func newServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the REST API server",
+		Long: `Run Watchface Builder as a web service.
+
+Endpoints:
+  POST /api/build                Build a watchface package
+  GET  /api/templates            List available templates
+  GET  /api/download/:filename   Download a built package
+
+Examples:
+  watchface-builder serve --port 8080
+  watchface-builder serve --port 8080 --artifact-dir ./artifacts --auth-token SECRET`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runServe,
+	}
+
+	serveCmd.Flags().StringVar(&serveHost, "host", "", "Host address to listen on (default all interfaces)")
+	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8080, "Port to listen on")
+	serveCmd.Flags().StringVar(&serveArtifactDir, "artifact-dir", "artifacts", "Directory where built packages are stored")
+	serveCmd.Flags().StringVar(&serveAuthToken, "auth-token", "", "Require this bearer token on every request")
+	serveCmd.Flags().StringVar(&serveCORSOrigins, "cors-origins", "", "Allowed CORS origins, comma-separated (default all)")
+
+	return serveCmd
+}
+
+func runServe(_ *cobra.Command, _ []string) error {
+	var origins []string
+	for _, origin := range strings.Split(serveCORSOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	handler := server.New(builder.NewBuilder(), server.Config{
+		ArtifactDir: serveArtifactDir,
+		AuthToken:   serveAuthToken,
+		CORSOrigins: origins,
+	})
+
+	addr := net.JoinHostPort(serveHost, strconv.Itoa(servePort))
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Println(msg("serve.running", addr))
+	fmt.Println(msg("serve.artifacts", serveArtifactDir))
+	return httpServer.ListenAndServe()
+}
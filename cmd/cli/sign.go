@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+var (
+	keygenOut   string
+	keygenForce bool
+	signKeyPath string
+	verifyKey   string
+	verifyJSON  bool
+)
+
+func newKeygenCmd() *cobra.Command {
+	keygenCmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an Ed25519 key pair for signing packages",
+		Long: `Generate an Ed25519 key pair.
+
+Writes <out>.key (PKCS#8 private key, keep it secret) and <out>.pub
+(PKIX public key, give it to whoever verifies your packages).
+
+Examples:
+  watchface-builder keygen --out keys/watchface`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runKeygen,
+	}
+
+	keygenCmd.Flags().StringVar(&keygenOut, "out", "watchface", "Output path prefix for the .key and .pub files")
+	keygenCmd.Flags().BoolVarP(&keygenForce, "force", "f", false, "Overwrite existing key files")
+
+	return keygenCmd
+}
+
+func newSignCmd() *cobra.Command {
+	signCmd := &cobra.Command{
+		Use:   "sign <zip>",
+		Short: "Sign an existing watchface package",
+		Long: `Add a signature manifest with per-file hashes and a detached Ed25519
+signature to a package, replacing any previous signature.
+
+Examples:
+  watchface-builder sign My_Watchface_v1.0.0_20250121_100000.zip --key watchface.key`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runSign,
+	}
+
+	signCmd.Flags().StringVar(&signKeyPath, "key", "", "Private key file (required)")
+	_ = signCmd.MarkFlagRequired("key")
+
+	return signCmd
+}
+
+func newVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify <zip>",
+		Short: "Verify the signature of a watchface package",
+		Long: `Verify a signed package offline against a trusted public key.
+
+Fails if the signature does not match the key, or if any file was added,
+removed or modified after signing.
+
+Examples:
+  watchface-builder verify My_Watchface_v1.0.0_20250121_100000.zip --key watchface.pub`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runVerify,
+	}
+
+	verifyCmd.Flags().StringVar(&verifyKey, "key", "", "Trusted public key file (required)")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Print the signed manifest as JSON")
+	_ = verifyCmd.MarkFlagRequired("key")
+
+	return verifyCmd
+}
+
+func runKeygen(_ *cobra.Command, _ []string) error {
+	keyPath, pubPath := keygenOut+".key", keygenOut+".pub"
+	if !keygenForce {
+		for _, path := range []string{keyPath, pubPath} {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+		}
+	}
+
+	publicKey, privateKey, err := builder.GenerateSigningKey()
+	if err != nil {
+		return err
+	}
+	privatePEM, err := builder.EncodePrivateKeyPEM(privateKey)
+	if err != nil {
+		return err
+	}
+	publicPEM, err := builder.EncodePublicKeyPEM(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyPath, privatePEM, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(pubPath, publicPEM, 0644); err != nil {
+		return err
+	}
+
+	fmt.Println(msg("keygen.private", keyPath))
+	fmt.Println(msg("keygen.public", pubPath))
+	return nil
+}
+
+func runSign(_ *cobra.Command, args []string) error {
+	privateKey, err := builder.LoadPrivateKey(signKeyPath)
+	if err != nil {
+		return err
+	}
+	if _, err := builder.SignPackage(args[0], privateKey); err != nil {
+		return err
+	}
+
+	fmt.Println(msg("sign.signed", args[0]))
+	return nil
+}
+
+func runVerify(_ *cobra.Command, args []string) error {
+	publicKey, err := builder.LoadPublicKey(verifyKey)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := builder.VerifyPackage(args[0], publicKey)
+	if err != nil {
+		if verifyJSON {
+			errorJSON, _ := json.MarshalIndent(map[string]interface{}{"valid": false, "error": err.Error()}, "", "  ")
+			fmt.Println(string(errorJSON))
+		} else {
+			fmt.Println(msg("verify.failed", err))
+		}
+		os.Exit(1)
+	}
+
+	if verifyJSON {
+		manifestJSON, _ := json.MarshalIndent(map[string]interface{}{"valid": true, "manifest": manifest}, "", "  ")
+		fmt.Println(string(manifestJSON))
+		return nil
+	}
+	fmt.Println(msg("verify.valid", len(manifest.Files)))
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+var (
+	validateJSON        bool
+	validateMaxSize     int64
+	validateAllowRemote bool
+)
+
+func newValidateCmd() *cobra.Command {
+	validateCmd := &cobra.Command{
+		Use:   "validate <zip>",
+		Short: "Check a watchface package against the package specification",
+		Long: `Validate a watchface ZIP locally.
+
+Checks that manifest.json parses, the entrypoint exists, every file referenced
+by index.html and the stylesheets is in the archive, the package is within the
+size limit and nothing is loaded from remote URLs. Exits non-zero on errors.
+
+Examples:
+  watchface-builder validate My_Watchface_v1.0.0_20250121_100000.zip
+  watchface-builder validate package.zip --json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runValidate,
+	}
+
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "Print the report as JSON")
+	validateCmd.Flags().Int64Var(&validateMaxSize, "max-size", builder.DefaultMaxPackageSize,
+		"Maximum uncompressed package size in bytes")
+	validateCmd.Flags().BoolVar(&validateAllowRemote, "allow-remote", false, "Allow references to remote URLs")
+
+	return validateCmd
+}
+
+func runValidate(_ *cobra.Command, args []string) error {
+	report, err := builder.ValidatePackage(args[0], builder.ValidateOptions{
+		MaxTotalSize: validateMaxSize,
+		AllowRemote:  validateAllowRemote,
+	})
+	if err != nil {
+		return err
+	}
+
+	if validateJSON {
+		reportJSON, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(reportJSON))
+	} else {
+		printValidationReport(report)
+	}
+
+	if !report.Valid {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func printValidationReport(report *builder.ValidationReport) {
+	fmt.Println(msg("validate.title", report.Package))
+	fmt.Println()
+	if report.Manifest != nil {
+		fmt.Println(msg("validate.name", report.Manifest.Name))
+		fmt.Println(msg("validate.version", report.Manifest.Version))
+		fmt.Println(msg("validate.entrypoint", report.Manifest.Entrypoint))
+	}
+	fmt.Println(msg("validate.files", report.FileCount))
+	fmt.Println(msg("validate.size", float64(report.TotalSize)/1024))
+	fmt.Println()
+
+	// Issue messages come from the builder and stay English; the code identifies the issue in any language
+	for _, issue := range report.Issues {
+		icon := "⚠️ "
+		if issue.Severity == builder.SeverityError {
+			icon = "❌"
+		}
+		if issue.File != "" {
+			fmt.Printf("  %s [%s] %s: %s\n", icon, issue.Code, issue.File, issue.Message)
+		} else {
+			fmt.Printf("  %s [%s] %s\n", icon, issue.Code, issue.Message)
+		}
+	}
+	if len(report.Issues) > 0 {
+		fmt.Println()
+	}
+
+	if report.Valid {
+		fmt.Println(msg("validate.valid", report.Warnings()))
+	} else {
+		fmt.Println(msg("validate.invalid", report.Errors(), report.Warnings()))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// validateHelperEnv names the package that the test binary, run again as a
+// helper process, validates through runValidate
+const validateHelperEnv = "WATCHFACE_VALIDATE_HELPER"
+
+func TestMain(m *testing.M) {
+	if zipPath := os.Getenv(validateHelperEnv); zipPath != "" {
+		validateJSON = true
+		if err := runValidate(nil, []string{zipPath}); err != nil {
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestValidateExitCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"valid", map[string]string{
+			"manifest.json": `{"name": "A", "version": "1.0.0", "author": "Ada", "entrypoint": "index.html"}`,
+			"index.html":    `<html></html>`,
+		}, 0},
+		{"missing manifest", map[string]string{"index.html": `<html></html>`}, 1},
+		{"missing entrypoint", map[string]string{
+			"manifest.json": `{"name": "A", "version": "1.0.0", "author": "Ada", "entrypoint": "index.html"}`,
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zipPath := filepath.Join(t.TempDir(), "package.zip")
+			out, err := os.Create(zipPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writer := zip.NewWriter(out)
+			for name, content := range tt.files {
+				entry, err := writer.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := entry.Write([]byte(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := out.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command(os.Args[0], "-test.run=^$")
+			cmd.Env = append(os.Environ(), validateHelperEnv+"="+zipPath)
+			output, err := cmd.Output()
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.want {
+				t.Errorf("validate exited with %d, want %d; output:\n%s", code, tt.want, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// BridgeVersion is the version of the watchface.js data bridge bundled into packages
+const BridgeVersion = "1.0.0"
+
+// BridgeFile is the package path of the data bridge
+const BridgeFile = "watchface.js"
+
+// Data permissions a face declares to read watch data through the bridge
+const (
+	PermissionBattery       = "battery"
+	PermissionSteps         = "steps"
+	PermissionHeartRate     = "heart_rate"
+	PermissionWeather       = "weather"
+	PermissionNotifications = "notifications"
+)
+
+var permissions = []string{
+	PermissionBattery,
+	PermissionSteps,
+	PermissionHeartRate,
+	PermissionWeather,
+	PermissionNotifications,
+}
+
+// Permissions returns the names of all data permissions
+func Permissions() []string {
+	return append([]string(nil), permissions...)
+}
+
+//go:embed watchface.js
+var bridgeSource string
+
+var bridgeTemplate = template.Must(template.New(BridgeFile).Parse(bridgeSource))
+
+// normalizePermissions checks permission names and returns them sorted and deduplicated
+func normalizePermissions(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, name := range names {
+		if !contains(permissions, name) {
+			return nil, invalidOptions("unknown permission %q (expected one of %s)", name, strings.Join(permissions, ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// addBridge adds watchface.js, restricted to the declared permissions, to the
+// package files and loads it from index.html ahead of the face's own scripts.
+// The bundled bridge replaces any copy in the sources so that its version
+// matches the manifest.
+func addBridge(files map[string][]byte, permissions []string) error {
+	declared, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = bridgeTemplate.Execute(&buf, struct {
+		Version     string
+		Permissions string
+	}{BridgeVersion, string(declared)})
+	if err != nil {
+		return err
+	}
+	files[BridgeFile] = buf.Bytes()
+
+	if index, ok := files["index.html"]; ok && !bytes.Contains(index, []byte(BridgeFile)) {
+		files["index.html"] = InjectScripts(index, BridgeFile)
+	}
+	return nil
+}
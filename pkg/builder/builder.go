@@ -2,42 +2,74 @@ package builder
 
 import (
 	"archive/zip"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"image/color"
-	"image/png"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
-
-	"github.com/fogleman/gg"
 )
 
 // Builder is the main watchface builder
-type Builder struct{}
+type Builder struct {
+	registry *Registry
+}
 
-// NewBuilder creates a new builder instance
+// NewBuilder creates a new builder instance using the default template registry
 func NewBuilder() *Builder {
-	return &Builder{}
+	return NewBuilderWithRegistry(DefaultRegistry())
+}
+
+// NewBuilderWithRegistry creates a new builder instance using the given template registry
+func NewBuilderWithRegistry(registry *Registry) *Builder {
+	return &Builder{registry: registry}
+}
+
+// Registry returns the template registry used by the builder
+func (b *Builder) Registry() *Registry {
+	return b.registry
 }
 
 // BuildOptions contains options for building a watchface
 type BuildOptions struct {
-	Name            string   // Watchface name (required)
-	Version         string   // Version number
-	Author          string   // Author name
-	Description     string   // Description
-	Tags            []string // Tags
-	Template        string   // Template type: simple, analog, digital, custom
-	CustomHTML      string   // Custom HTML content (for custom template)
-	CustomCSS       string   // Custom CSS content (for custom template)
-	CustomJS        string   // Custom JS content (for custom template)
-	OutputPath      string   // Output directory
-	GeneratePreview bool     // Whether to generate preview image
+	Name            string             // Watchface name (required)
+	Version         string             // Version number
+	Author          string             // Author name
+	Description     string             // Description
+	Tags            []string           // Tags
+	Template        string             // Template ID, e.g. simple, analog, digital, custom
+	Devices         []string           // Target device profile IDs, the first one is laid out for
+	Permissions     []string           // Watch data the face reads through watchface.js, see Permissions
+	Complications   []Complication     // Generated complications, at most one per slot
+	Theme           Theme              // Overrides of the template's default theme
+	Font            []byte             // TrueType font bundled, subset to the characters used, as FontFamilyName
+	Locales         []string           // Locale tags, see Locales; the first one is used by default (DefaultLocale if empty)
+	CustomHTML      string             // Custom HTML content (for custom template)
+	CustomCSS       string             // Custom CSS content (for custom template)
+	CustomJS        string             // Custom JS content (for custom template)
+	SourceDir       string             // Directory packaged instead of template files
+	Include         []string           // Glob patterns of source files to include (default all)
+	Exclude         []string           // Glob patterns of source files to exclude
+	Assets          map[string][]byte  // Extra files keyed by package path, overriding template files
+	OutputPath      string             // Output directory
+	GeneratePreview bool               // Whether to generate preview image
+	StoreImages     bool               // Also generate store listing images (requires GeneratePreview)
+	PreviewTime     time.Time          // Time shown in previews, zero uses DefaultPreviewTime
+	MinifyHTML      bool               // Minify HTML files, including inline styles and scripts
+	MinifyCSS       bool               // Minify CSS files
+	MinifyJS        bool               // Minify JS files
+	OptimizeImages  bool               // Recompress PNG files losslessly
+	Inline          bool               // Fold stylesheets, scripts, small images and fonts into index.html
+	Reproducible    bool               // Produce byte-identical output for identical input
+	SigningKey      ed25519.PrivateKey // Sign the package with this key, if set; never passed to templates
 }
 
 // BuildResult contains the result of a build
@@ -49,6 +81,7 @@ type BuildResult struct {
 	FileCount int                    // Number of files in package
 	Files     []string               // List of files in package
 	Manifest  string                 // manifest.json content
+	Signature string                 // Base64 Ed25519 signature, if signed
 	Error     string                 // Error message if failed
 	Metadata  map[string]interface{} // Additional metadata
 }
@@ -61,13 +94,26 @@ type ManifestData struct {
 	Description string    `json:"description,omitempty"`
 	Entrypoint  string    `json:"entrypoint"`
 	Tags        []string  `json:"tags,omitempty"`
+	Devices     []string  `json:"devices,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	Bridge      string    `json:"bridge,omitempty"`  // Version of the bundled watchface.js
+	Layout      string    `json:"layout,omitempty"`  // LayoutInline for single-file packages
+	Locales     []string  `json:"locales,omitempty"` // Locales bundled in locale.js
 	CreatedAt   time.Time `json:"created_at"`
 }
 
 // Build builds a watchface package
 func (b *Builder) Build(options BuildOptions) (*BuildResult, error) {
 	// Validate options
-	if err := b.validateOptions(options); err != nil {
+	if err := b.validateOptions(&options); err != nil {
+		return &BuildResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	buildTime, err := resolveBuildTime(options.Reproducible)
+	if err != nil {
 		return &BuildResult{
 			Success: false,
 			Error:   err.Error(),
@@ -83,38 +129,60 @@ func (b *Builder) Build(options BuildOptions) (*BuildResult, error) {
 		_ = os.RemoveAll(path)
 	}(tempDir)
 
-	// Generate files based on template
-	files, err := b.generateTemplateFiles(options)
+	// Generate files based on template or source directory
+	files, err := b.generatePackageFiles(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate template files: %w", err)
+		return nil, err
+	}
+
+	_, localized := files[LocaleFile]
+
+	// Fold the files into a single document
+	var inlined []string
+	if options.Inline {
+		inlined = inlineFiles(files)
 	}
 
 	// Write files to temp directory
 	fileList := []string{}
 	for fileName, content := range files {
-		filePath := filepath.Join(tempDir, fileName)
-		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		filePath := filepath.Join(tempDir, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", fileName, err)
+		}
+		if err := os.WriteFile(filePath, content, 0644); err != nil {
 			return nil, fmt.Errorf("failed to write file %s: %w", fileName, err)
 		}
 		fileList = append(fileList, fileName)
 	}
 
-	// Generate preview image if requested
+	// Generate preview images if requested
 	if options.GeneratePreview {
-		previewPath := filepath.Join(tempDir, "preview.png")
-		if err := b.generatePreviewImage(previewPath, options.Name, options.Template); err == nil {
-			fileList = append(fileList, "preview.png")
+		previews, err := b.generatePreviews(tempDir, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate preview: %w", err)
 		}
+		fileList = append(fileList, previews...)
+	}
+
+	// Minify and recompress the package files
+	savings, err := optimizeAssets(tempDir, fileList, options)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate manifest.json
-	manifest := b.generateManifest(options)
+	manifest := b.generateManifest(options, buildTime)
+	if localized {
+		manifest.Locales = options.ResolvedLocales()
+	}
 	manifestJSON, _ := json.MarshalIndent(manifest, "", "  ")
 	manifestPath := filepath.Join(tempDir, "manifest.json")
 	if err := os.WriteFile(manifestPath, manifestJSON, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write manifest.json: %w", err)
 	}
 	fileList = append(fileList, "manifest.json")
+	sort.Strings(fileList)
 
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(options.OutputPath, 0755); err != nil {
@@ -122,17 +190,27 @@ func (b *Builder) Build(options BuildOptions) (*BuildResult, error) {
 	}
 
 	// Create ZIP file
-	timestamp := time.Now().Format("20060102_150405")
+	timestamp := buildTime.Format("20060102_150405")
 	zipFileName := fmt.Sprintf("%s_v%s_%s.zip",
 		sanitizeFileName(options.Name),
 		options.Version,
 		timestamp)
 	zipPath := filepath.Join(options.OutputPath, zipFileName)
 
-	if err := b.createZip(tempDir, zipPath, fileList); err != nil {
+	if err := b.createZip(tempDir, zipPath, fileList, buildTime); err != nil {
 		return nil, fmt.Errorf("failed to create ZIP: %w", err)
 	}
 
+	// Sign the package
+	var signature string
+	if options.SigningKey != nil {
+		signature, err = SignPackage(zipPath, options.SigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign package: %w", err)
+		}
+		fileList = append(fileList, SignatureManifestFile, SignatureFile)
+	}
+
 	// Calculate file hash
 	fileHash, err := calculateFileHash(zipPath)
 	if err != nil {
@@ -151,13 +229,34 @@ func (b *Builder) Build(options BuildOptions) (*BuildResult, error) {
 		FileCount: len(fileList),
 		Files:     fileList,
 		Manifest:  string(manifestJSON),
+		Signature: signature,
+		Metadata: map[string]interface{}{
+			"build_time":    buildTime,
+			"reproducible":  options.Reproducible,
+			"asset_savings": savings,
+			"inlined_files": inlined,
+		},
 	}, nil
 }
 
-// validateOptions validates build options
-func (b *Builder) validateOptions(options BuildOptions) error {
+// OptionsError reports build options that failed validation
+type OptionsError struct {
+	Message string
+}
+
+func (e *OptionsError) Error() string {
+	return e.Message
+}
+
+// invalidOptions returns an OptionsError with a formatted message
+func invalidOptions(format string, args ...interface{}) error {
+	return &OptionsError{Message: fmt.Sprintf(format, args...)}
+}
+
+// validateOptions applies defaults to and validates build options
+func (b *Builder) validateOptions(options *BuildOptions) error {
 	if options.Name == "" {
-		return fmt.Errorf("name is required")
+		return invalidOptions("name is required")
 	}
 	if options.Version == "" {
 		options.Version = "1.0.0"
@@ -165,102 +264,175 @@ func (b *Builder) validateOptions(options BuildOptions) error {
 	if options.Template == "" {
 		options.Template = "simple"
 	}
-	validTemplates := map[string]bool{
-		"simple": true, "analog": true, "digital": true, "custom": true,
+	for fileName := range options.Assets {
+		if err := validatePackagePath(fileName); err != nil {
+			return invalidOptions("invalid asset %q: %v", fileName, err)
+		}
+	}
+	if len(options.Font) > 0 {
+		if _, err := parseFont(options.Font); err != nil {
+			return invalidOptions("%v", err)
+		}
+	}
+	if err := options.ResolvedTheme().Validate(); err != nil {
+		return invalidOptions("%v", err)
+	}
+	locales, err := normalizeLocales(options.Locales)
+	if err != nil {
+		return err
+	}
+	options.Locales = locales
+	options.Complications = append([]Complication(nil), options.Complications...)
+	needed, err := validateComplications(options.Complications)
+	if err != nil {
+		return err
+	}
+	permissions, err := normalizePermissions(append(append([]string(nil), options.Permissions...), needed...))
+	if err != nil {
+		return err
+	}
+	options.Permissions = permissions
+	if options.SigningKey != nil && len(options.SigningKey) != ed25519.PrivateKeySize {
+		return invalidOptions("invalid signing key")
+	}
+	devices := make([]DeviceProfile, 0, len(options.Devices))
+	for _, id := range options.Devices {
+		device, ok := LookupDevice(id)
+		if !ok {
+			return invalidOptions("unknown device: %s", id)
+		}
+		devices = append(devices, device)
+	}
+	if options.SourceDir != "" {
+		if info, err := os.Stat(options.SourceDir); err != nil || !info.IsDir() {
+			return invalidOptions("source directory not found: %s", options.SourceDir)
+		}
+		return nil
 	}
-	if !validTemplates[options.Template] {
-		return fmt.Errorf("invalid template: %s", options.Template)
+
+	tmpl, ok := b.registry.Lookup(options.Template)
+	if !ok {
+		return invalidOptions("invalid template: %s", options.Template)
 	}
-	if options.Template == "custom" && options.CustomHTML == "" {
-		return fmt.Errorf("custom template requires customHTML")
+	for _, device := range devices {
+		if err := checkDeviceSupport(device, tmpl); err != nil {
+			return invalidOptions("%v", err)
+		}
+	}
+	if validator, ok := tmpl.(OptionsValidator); ok {
+		if err := validator.ValidateOptions(*options); err != nil {
+			return invalidOptions("%v", err)
+		}
 	}
 	return nil
 }
 
-// generateTemplateFiles generates template files based on template type
-func (b *Builder) generateTemplateFiles(options BuildOptions) (map[string]string, error) {
-	switch options.Template {
-	case "simple":
-		return b.generateSimpleTemplate(options), nil
-	case "analog":
-		return b.generateAnalogTemplate(options), nil
-	case "digital":
-		return b.generateDigitalTemplate(options), nil
-	case "custom":
-		return b.generateCustomTemplate(options), nil
-	default:
+// GenerateFiles validates options and returns the face files a build would package,
+// keyed by package path. Previews, manifest and signature are not included.
+func (b *Builder) GenerateFiles(options BuildOptions) (map[string][]byte, error) {
+	if err := b.validateOptions(&options); err != nil {
+		return nil, err
+	}
+	return b.generatePackageFiles(options)
+}
+
+// generatePackageFiles generates the face files and adds the extra assets
+func (b *Builder) generatePackageFiles(options BuildOptions) (map[string][]byte, error) {
+	files, err := b.generateFiles(options)
+	if err != nil {
+		return nil, err
+	}
+	for fileName, content := range options.Assets {
+		files[fileName] = content
+	}
+	if len(options.Complications) > 0 {
+		if err := addComplications(files, options); err != nil {
+			return nil, fmt.Errorf("failed to add complications: %w", err)
+		}
+	}
+	if err := addLocales(files, options); err != nil {
+		return nil, fmt.Errorf("failed to add locales: %w", err)
+	}
+	if len(options.Permissions) > 0 {
+		if err := addBridge(files, options.Permissions); err != nil {
+			return nil, fmt.Errorf("failed to add data bridge: %w", err)
+		}
+	}
+	if len(options.Font) > 0 {
+		if err := addFont(files, options.Font); err != nil {
+			return nil, fmt.Errorf("failed to add font: %w", err)
+		}
+	}
+	return files, nil
+}
+
+// generateFiles generates the package files from the source directory or the template
+func (b *Builder) generateFiles(options BuildOptions) (map[string][]byte, error) {
+	if options.SourceDir == "" {
+		files, err := b.generateTemplateFiles(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate template files: %w", err)
+		}
+		return files, nil
+	}
+
+	files, err := collectSourceFiles(options.SourceDir, options.Include, options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	// Generated files take precedence over stale copies in the source tree
+	delete(files, "manifest.json")
+	if options.GeneratePreview {
+		for fileName := range files {
+			if reservedFiles[fileName] || strings.HasPrefix(fileName, previewsDir+"/") ||
+				strings.HasPrefix(fileName, storeDir+"/") {
+				delete(files, fileName)
+			}
+		}
+	}
+	if _, ok := files["index.html"]; !ok {
+		return nil, invalidOptions("source directory %s has no index.html", options.SourceDir)
+	}
+	return files, nil
+}
+
+// generateTemplateFiles generates template files using the registered template
+func (b *Builder) generateTemplateFiles(options BuildOptions) (map[string][]byte, error) {
+	tmpl, ok := b.registry.Lookup(options.Template)
+	if !ok {
 		return nil, fmt.Errorf("unknown template: %s", options.Template)
 	}
+	options.SigningKey = nil // Only signing needs the key, templates never see it
+	return tmpl.Generate(options)
 }
 
 // generateManifest generates manifest.json
-func (b *Builder) generateManifest(options BuildOptions) ManifestData {
-	return ManifestData{
+func (b *Builder) generateManifest(options BuildOptions, createdAt time.Time) ManifestData {
+	manifest := ManifestData{
 		Name:        options.Name,
 		Version:     options.Version,
 		Author:      options.Author,
 		Description: options.Description,
 		Entrypoint:  "index.html",
 		Tags:        options.Tags,
-		CreatedAt:   time.Now(),
+		Devices:     options.Devices,
+		Permissions: options.Permissions,
+		CreatedAt:   createdAt,
 	}
-}
-
-// generatePreviewImage generates a preview image
-func (b *Builder) generatePreviewImage(outputPath, name, template string) error {
-	const width = 512
-	const height = 512
-
-	dc := gg.NewContext(width, height)
-
-	// Background gradient based on template
-	var bgColor1, bgColor2 color.Color
-	switch template {
-	case "analog":
-		bgColor1 = color.RGBA{245, 245, 245, 255}
-		bgColor2 = color.RGBA{220, 220, 220, 255}
-	case "digital":
-		bgColor1 = color.RGBA{10, 10, 30, 255}
-		bgColor2 = color.RGBA{30, 30, 60, 255}
-	default: // simple
-		bgColor1 = color.RGBA{74, 144, 226, 255}
-		bgColor2 = color.RGBA{142, 84, 233, 255}
-	}
-
-	// Draw gradient background
-	for y := 0; y < height; y++ {
-		r1, g1, b1, a1 := bgColor1.RGBA()
-		r2, g2, b2, a2 := bgColor2.RGBA()
-		ratio := float64(y) / float64(height)
-		r := uint8((float64(r1)*(1-ratio) + float64(r2)*ratio) / 256)
-		g := uint8((float64(g1)*(1-ratio) + float64(g2)*ratio) / 256)
-		b := uint8((float64(b1)*(1-ratio) + float64(b2)*ratio) / 256)
-		a := uint8((float64(a1)*(1-ratio) + float64(a2)*ratio) / 256)
-		dc.SetRGB255(int(r), int(g), int(b))
-		dc.SetRGBA255(int(r), int(g), int(b), int(a))
-		dc.DrawLine(0, float64(y), float64(width), float64(y))
-		dc.Stroke()
-	}
-
-	// Draw text
-	dc.SetRGB(1, 1, 1)
-	dc.DrawStringAnchored(name, float64(width)/2, float64(height)/2, 0.5, 0.5)
-
-	// Save image
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
+	if len(options.Permissions) > 0 {
+		manifest.Bridge = BridgeVersion
 	}
-	defer func(file *os.File) {
-		_ = file.Close()
-	}(file)
-
-	img := dc.Image()
-	return png.Encode(file, img)
+	if options.Inline {
+		manifest.Layout = LayoutInline
+	}
+	return manifest
 }
 
-// createZip creates a ZIP file from a directory
-func (b *Builder) createZip(sourceDir, zipPath string, files []string) error {
+// createZip creates a ZIP file from a directory.
+// File names are slash-separated paths relative to sourceDir; parent
+// directories get their own entries so nested layouts are preserved.
+// Every entry gets the same modification time and fixed permissions.
+func (b *Builder) createZip(sourceDir, zipPath string, files []string, modified time.Time) error {
 	zipFile, err := os.Create(zipPath)
 	if err != nil {
 		return err
@@ -274,14 +446,30 @@ func (b *Builder) createZip(sourceDir, zipPath string, files []string) error {
 		_ = zipWriter.Close()
 	}(zipWriter)
 
+	dirs := map[string]bool{}
 	for _, fileName := range files {
-		filePath := filepath.Join(sourceDir, fileName)
+		var parents []string
+		for dir := path.Dir(fileName); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			dirs[parents[i]] = true
+			header := &zip.FileHeader{Name: parents[i] + "/", Method: zip.Store, Modified: modified}
+			header.SetMode(fs.ModeDir | 0755)
+			if _, err := zipWriter.CreateHeader(header); err != nil {
+				return fmt.Errorf("failed to create zip directory %s: %w", parents[i], err)
+			}
+		}
+
+		filePath := filepath.Join(sourceDir, filepath.FromSlash(fileName))
 		fileContent, err := os.ReadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("failed to read file %s: %w", fileName, err)
 		}
 
-		zipFileWriter, err := zipWriter.Create(fileName)
+		header := &zip.FileHeader{Name: fileName, Method: zip.Deflate, Modified: modified}
+		header.SetMode(0644)
+		zipFileWriter, err := zipWriter.CreateHeader(header)
 		if err != nil {
 			return fmt.Errorf("failed to create zip entry %s: %w", fileName, err)
 		}
@@ -291,6 +479,52 @@ func (b *Builder) createZip(sourceDir, zipPath string, files []string) error {
 		}
 	}
 
+	return zipWriter.Close()
+}
+
+// defaultSourceDateEpoch is used by reproducible builds when SOURCE_DATE_EPOCH is unset.
+// It is the earliest time a ZIP entry can represent.
+var defaultSourceDateEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// resolveBuildTime returns the time stamped into the package. Reproducible
+// builds use SOURCE_DATE_EPOCH (https://reproducible-builds.org/specs/source-date-epoch/).
+func resolveBuildTime(reproducible bool) (time.Time, error) {
+	if !reproducible {
+		return time.Now(), nil
+	}
+
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return defaultSourceDateEpoch, nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, invalidOptions("invalid SOURCE_DATE_EPOCH: %s", epoch)
+	}
+	buildTime := time.Unix(seconds, 0).UTC()
+	if buildTime.Before(defaultSourceDateEpoch) {
+		buildTime = defaultSourceDateEpoch
+	}
+	return buildTime, nil
+}
+
+// reservedFiles are generated by the builder and cannot be supplied as assets
+var reservedFiles = map[string]bool{
+	"manifest.json": true,
+	"preview.png":   true,
+}
+
+// validatePackagePath checks that a slash-separated path stays inside the package
+func validatePackagePath(name string) error {
+	if name == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if err := checkEntryPath(name); err != nil {
+		return err
+	}
+	if reservedFiles[name] {
+		return fmt.Errorf("%s is generated by the builder", name)
+	}
 	return nil
 }
 
@@ -316,8 +550,13 @@ func calculateFileHash(filePath string) (string, error) {
 		_ = file.Close()
 	}(file)
 
+	return hashReader(file)
+}
+
+// hashReader calculates the SHA256 hash of everything read from r
+func hashReader(r io.Reader) (string, error) {
 	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
+	if _, err := io.Copy(hash, r); err != nil {
 		return "", err
 	}
 
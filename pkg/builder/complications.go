@@ -0,0 +1,241 @@
+package builder
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Complication fills a slot of the face with a provider of information
+type Complication struct {
+	Slot     string `json:"slot" yaml:"slot"`                             // top, bottom, left, right or center
+	Provider string `json:"provider" yaml:"provider"`                     // See ComplicationProviders
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"` // IANA zone of the timezone provider
+	Label    string `json:"label,omitempty" yaml:"label,omitempty"`       // Caption, defaults to the city for timezones
+}
+
+// Complication slots
+const (
+	SlotTop    = "top"
+	SlotBottom = "bottom"
+	SlotLeft   = "left"
+	SlotRight  = "right"
+	SlotCenter = "center"
+)
+
+var complicationSlots = []string{SlotTop, SlotBottom, SlotLeft, SlotRight, SlotCenter}
+
+// Complication providers
+const (
+	ProviderDate     = "date"     // Month and day
+	ProviderWeekday  = "weekday"  // Short weekday name
+	ProviderBattery  = "battery"  // Battery level ring, needs the battery permission
+	ProviderSteps    = "steps"    // Step count, needs the steps permission
+	ProviderTimezone = "timezone" // Time in a second timezone
+)
+
+var complicationProviders = []string{ProviderDate, ProviderWeekday, ProviderBattery, ProviderSteps, ProviderTimezone}
+
+// providerPermissions are the data permissions each provider reads through watchface.js
+var providerPermissions = map[string]string{
+	ProviderBattery: PermissionBattery,
+	ProviderSteps:   PermissionSteps,
+}
+
+// ComplicationSlots returns the names of all complication slots
+func ComplicationSlots() []string {
+	return append([]string(nil), complicationSlots...)
+}
+
+// ComplicationProviders returns the names of all complication providers
+func ComplicationProviders() []string {
+	return append([]string(nil), complicationProviders...)
+}
+
+// ParseComplication parses a command-line complication such as "top=date" or
+// "bottom=timezone:Europe/London"
+func ParseComplication(spec string) (Complication, error) {
+	slot, provider, ok := strings.Cut(spec, "=")
+	if !ok {
+		return Complication{}, fmt.Errorf("invalid complication %q (expected slot=provider)", spec)
+	}
+	c := Complication{Slot: strings.TrimSpace(slot), Provider: strings.TrimSpace(provider)}
+	if name, zone, ok := strings.Cut(c.Provider, ":"); ok {
+		c.Provider, c.Timezone = name, zone
+	}
+	return c, nil
+}
+
+// The zone tables cover these years, so that builds are reproducible. Past the
+// end, complications.js asks Intl for the offset where the watch supports it and
+// otherwise keeps the last offset of the table
+var (
+	zoneTableStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	zoneTableEnd   = time.Date(2036, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+//go:embed complications.css
+var complicationsCSS string
+
+//go:embed complications.js
+var complicationsJS string
+
+var (
+	complicationsCSSTemplate = texttemplate.Must(texttemplate.New("complications.css").Parse(complicationsCSS))
+	complicationsJSTemplate  = texttemplate.Must(texttemplate.New("complications.js").Parse(complicationsJS))
+)
+
+var complicationMarkup = template.Must(template.New("complications").Parse(`
+{{- range .}}<div class="wf-complication wf-slot-{{.Slot}}" data-provider="{{.Provider}}"{{with .Timezone}} data-zone="{{.}}"{{end}}>
+{{- if eq .Provider "battery"}}
+    <div class="wf-ring-gauge">
+        <svg viewBox="0 0 36 36"><circle cx="18" cy="18" r="15.9155" fill="none" stroke="currentColor" stroke-opacity="0.25" stroke-width="3"/><circle class="wf-ring" cx="18" cy="18" r="15.9155" fill="none" stroke="currentColor" stroke-width="3" stroke-dasharray="100" stroke-dashoffset="100"/></svg>
+        <span class="wf-value">--</span>
+    </div>
+{{- else}}
+    <span class="wf-value">--</span>
+{{- end}}
+{{- with .Label}}
+    <span class="wf-label">{{.}}</span>
+{{- end}}
+</div>
+{{end}}`))
+
+// validateComplications checks the complications and returns the data permissions
+// their providers need. Slot names are normalised and timezone labels defaulted.
+func validateComplications(complications []Complication) ([]string, error) {
+	var needed []string
+	used := map[string]bool{}
+	for i := range complications {
+		c := &complications[i]
+		if c.Slot == "centre" {
+			c.Slot = SlotCenter
+		}
+		if !contains(complicationSlots, c.Slot) {
+			return nil, invalidOptions("unknown complication slot %q (expected one of %s)",
+				c.Slot, strings.Join(complicationSlots, ", "))
+		}
+		if used[c.Slot] {
+			return nil, invalidOptions("complication slot %s is used more than once", c.Slot)
+		}
+		used[c.Slot] = true
+
+		if !contains(complicationProviders, c.Provider) {
+			return nil, invalidOptions("unknown complication provider %q (expected one of %s)",
+				c.Provider, strings.Join(complicationProviders, ", "))
+		}
+		if c.Provider == ProviderTimezone {
+			if c.Timezone == "" {
+				return nil, invalidOptions("complication %s: the timezone provider needs a timezone", c.Slot)
+			}
+			if _, err := time.LoadLocation(c.Timezone); err != nil {
+				return nil, invalidOptions("complication %s: unknown timezone %q", c.Slot, c.Timezone)
+			}
+			if c.Label == "" {
+				c.Label = strings.ReplaceAll(path.Base(c.Timezone), "_", " ")
+			}
+		} else if c.Timezone != "" {
+			return nil, invalidOptions("complication %s: only the timezone provider takes a timezone", c.Slot)
+		}
+		if permission, ok := providerPermissions[c.Provider]; ok {
+			needed = append(needed, permission)
+		}
+	}
+	return needed, nil
+}
+
+// addComplications adds complications.css and complications.js to the package
+// files and places the complication markup at the end of index.html's body
+func addComplications(files map[string][]byte, options BuildOptions) error {
+	index, ok := files["index.html"]
+	if !ok {
+		return fmt.Errorf("complications need an index.html")
+	}
+
+	fontSize := "5vmin"
+	if device, ok := options.PrimaryDevice(); ok {
+		fontSize = fmt.Sprintf("%dpx", percent(device.SafeSize(), 8))
+	}
+	// Complications take the theme's foreground on the built-in templates
+	color := "inherit"
+	if _, ok := builtinThemes[options.Template]; ok && options.SourceDir == "" {
+		color = options.ResolvedTheme().Foreground
+	}
+	var css bytes.Buffer
+	err := complicationsCSSTemplate.Execute(&css, struct{ Color, FontSize string }{color, fontSize})
+	if err != nil {
+		return err
+	}
+
+	zones := map[string][][2]int64{}
+	for _, c := range options.Complications {
+		if c.Timezone != "" {
+			table, err := zoneTable(c.Timezone)
+			if err != nil {
+				return err
+			}
+			zones[c.Timezone] = table
+		}
+	}
+	zonesJSON, err := json.Marshal(zones)
+	if err != nil {
+		return err
+	}
+	var js bytes.Buffer
+	err = complicationsJSTemplate.Execute(&js, struct {
+		Zones    string
+		ZonesEnd int64
+	}{string(zonesJSON), zoneTableEnd.UnixMilli()})
+	if err != nil {
+		return err
+	}
+
+	var markup bytes.Buffer
+	if err := complicationMarkup.Execute(&markup, options.Complications); err != nil {
+		return err
+	}
+	markup.WriteString("<script src=\"complications.js\"></script>\n")
+
+	index = insertBeforeClosing(index, "</head", []byte("<link rel=\"stylesheet\" href=\"complications.css\">\n"))
+	files["index.html"] = insertBeforeClosing(index, "</body", markup.Bytes())
+	files["complications.css"] = css.Bytes()
+	files["complications.js"] = js.Bytes()
+	return nil
+}
+
+// zoneTable returns the UTC offsets of a zone as [start in Unix milliseconds,
+// offset in minutes] pairs, one per transition
+func zoneTable(name string) ([][2]int64, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var table [][2]int64
+	for t := zoneTableStart.In(loc); t.Before(zoneTableEnd); {
+		_, offset := t.Zone()
+		table = append(table, [2]int64{t.UnixMilli(), int64(offset / 60)})
+		_, next := t.ZoneBounds()
+		if next.IsZero() {
+			break
+		}
+		t = next
+	}
+	return table, nil
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
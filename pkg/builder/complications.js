@@ -0,0 +1,104 @@
+// Complications generated by Watchface Builder. Each .wf-complication element
+// names its provider in data-provider; clocks update every second, formatted by
+// locale.js, and watch data arrives through watchface.js.
+(function () {
+    var ZONES = {{.Zones}};
+    var ZONES_END = {{.ZonesEnd}};
+    var locale = window.watchfaceLocale;
+    var RING_LENGTH = 100;
+
+    var formats = {};
+
+    // intlOffset returns the UTC offset in minutes of a zone at a time from the
+    // watch's own zone database, or null if it has none
+    function intlOffset(zone, time) {
+        try {
+            if (!formats[zone]) {
+                formats[zone] = new Intl.DateTimeFormat('en-US', {
+                    timeZone: zone, hour12: false,
+                    year: 'numeric', month: 'numeric', day: 'numeric', hour: 'numeric', minute: 'numeric'
+                });
+            }
+            // M/D/YYYY, HH:MM, with midnight sometimes as hour 24
+            var parts = formats[zone].format(new Date(time)).match(/\d+/g);
+            var local = Date.UTC(+parts[2], parts[0] - 1, +parts[1], parts[3] % 24, +parts[4]);
+            return Math.round((local - Math.floor(time / 60000) * 60000) / 60000);
+        } catch (e) {
+            return null;
+        }
+    }
+
+    // zoneOffset returns the UTC offset in minutes of a zone at a time, from a
+    // table of [start, offset] transitions. Past the end of the table it asks
+    // Intl, where the watch supports it, and otherwise keeps the last offset
+    function zoneOffset(zone, time) {
+        if (time >= ZONES_END) {
+            var offset = intlOffset(zone, time);
+            if (offset !== null) {
+                return offset;
+            }
+        }
+        var table = ZONES[zone];
+        var offset = table[0][1];
+        for (var i = 0; i < table.length && table[i][0] <= time; i++) {
+            offset = table[i][1];
+        }
+        return offset;
+    }
+
+    function setText(slot, text) {
+        slot.querySelector('.wf-value').textContent = text;
+    }
+
+    var clocks = {
+        date: function (slot, now) {
+            setText(slot, locale.formatMonthDay(now));
+        },
+        weekday: function (slot, now) {
+            setText(slot, locale.shortWeekday(now.getDay()));
+        },
+        timezone: function (slot, now) {
+            var zoned = new Date(now.getTime() + zoneOffset(slot.getAttribute('data-zone'), now.getTime()) * 60000);
+            setText(slot, locale.formatTime(zoned, false, true));
+        }
+    };
+
+    var data = {
+        battery: function (slot, value) {
+            var level = Math.max(0, Math.min(100, value.level));
+            slot.querySelector('.wf-ring').setAttribute('stroke-dashoffset', String(RING_LENGTH - level));
+            setText(slot, level + '%');
+        },
+        steps: function (slot, value) {
+            setText(slot, String(value.count));
+        }
+    };
+
+    var slots = document.querySelectorAll('.wf-complication');
+    var ticking = [];
+    for (var i = 0; i < slots.length; i++) {
+        var slot = slots[i];
+        var provider = slot.getAttribute('data-provider');
+        if (clocks[provider]) {
+            ticking.push(slot);
+        } else if (data[provider] && window.watchface) {
+            window.watchface.on(provider, (function (slot, update) {
+                return function (value) {
+                    update(slot, value);
+                };
+            })(slot, data[provider]));
+        }
+    }
+
+    function tick() {
+        var now = new Date();
+        for (var i = 0; i < ticking.length; i++) {
+            clocks[ticking[i].getAttribute('data-provider')](ticking[i], now);
+        }
+    }
+
+    if (ticking.length > 0) {
+        tick();
+        setInterval(tick, 1000);
+    }
+})();
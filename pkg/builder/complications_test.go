@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"testing"
+	"time"
+)
+
+// tableOffset returns the offset in minutes a zone table gives at a time, as
+// zoneOffset in complications.js does
+func tableOffset(table [][2]int64, at time.Time) int64 {
+	offset := table[0][1]
+	for _, entry := range table {
+		if entry[0] > at.UnixMilli() {
+			break
+		}
+		offset = entry[1]
+	}
+	return offset
+}
+
+func TestZoneTableDaylightSaving(t *testing.T) {
+	table, err := zoneTable("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// London moves to BST at 01:00 UTC on the last Sunday of March and back at
+	// 01:00 UTC on the last Sunday of October
+	tests := []struct {
+		at   time.Time
+		want int64
+	}{
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 3, 31, 0, 59, 59, 0, time.UTC), 0},
+		{time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC), 60},
+		{time.Date(2024, 10, 27, 0, 59, 59, 0, time.UTC), 60},
+		{time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC), 0},
+		{time.Date(2035, 3, 25, 0, 59, 59, 0, time.UTC), 0},
+		{time.Date(2035, 3, 25, 1, 0, 0, 0, time.UTC), 60},
+		{time.Date(2035, 12, 31, 23, 59, 59, 0, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		if got := tableOffset(table, tt.at); got != tt.want {
+			t.Errorf("offset at %s = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+
+	// Two transitions a year, and none past the end of the table
+	if want := 1 + 2*12; len(table) != want {
+		t.Errorf("table has %d entries, want %d", len(table), want)
+	}
+	if last := table[len(table)-1][0]; last >= zoneTableEnd.UnixMilli() {
+		t.Errorf("last transition at %d, past the end of the table", last)
+	}
+}
+
+func TestZoneTableFixedOffset(t *testing.T) {
+	table, err := zoneTable("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 1 || table[0][1] != 330 {
+		t.Errorf("table = %v, want a single offset of 330", table)
+	}
+	if _, err := zoneTable("Mars/Olympus_Mons"); err == nil {
+		t.Error("zoneTable() accepted an unknown zone")
+	}
+}
@@ -0,0 +1,234 @@
+package builder
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Shape is the physical shape of a watch screen
+type Shape string
+
+const (
+	ShapeRound  Shape = "round"
+	ShapeSquare Shape = "square"
+	ShapeRect   Shape = "rect"
+)
+
+// JSLevel is the newest ECMAScript edition a WebView supports
+type JSLevel int
+
+const (
+	ES5 JSLevel = iota
+	ES2015
+	ES2017
+	ES2020
+)
+
+var jsLevelNames = []string{"es5", "es2015", "es2017", "es2020"}
+
+func (l JSLevel) String() string {
+	if l < 0 || int(l) >= len(jsLevelNames) {
+		return fmt.Sprintf("JSLevel(%d)", int(l))
+	}
+	return jsLevelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l JSLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *JSLevel) UnmarshalText(text []byte) error {
+	level, err := ParseJSLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseJSLevel parses a JS level name such as "es2015"
+func ParseJSLevel(name string) (JSLevel, error) {
+	for i, levelName := range jsLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return JSLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown JS level %q (expected one of %s)", name, strings.Join(jsLevelNames, ", "))
+}
+
+// CSSLevel is the CSS feature level a WebView supports
+type CSSLevel int
+
+const (
+	CSS2      CSSLevel = iota // CSS 2.1
+	CSS3                      // Flexbox, gradients, transforms, animations
+	CSSModern                 // CSS3 plus custom properties and calc()
+)
+
+var cssLevelNames = []string{"css2", "css3", "modern"}
+
+func (l CSSLevel) String() string {
+	if l < 0 || int(l) >= len(cssLevelNames) {
+		return fmt.Sprintf("CSSLevel(%d)", int(l))
+	}
+	return cssLevelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l CSSLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *CSSLevel) UnmarshalText(text []byte) error {
+	level, err := ParseCSSLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseCSSLevel parses a CSS level name such as "css3"
+func ParseCSSLevel(name string) (CSSLevel, error) {
+	for i, levelName := range cssLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return CSSLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown CSS level %q (expected one of %s)", name, strings.Join(cssLevelNames, ", "))
+}
+
+// DeviceProfile describes the screen and WebView of a target watch
+type DeviceProfile struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Width  int      `json:"width"`  // Screen width in physical pixels
+	Height int      `json:"height"` // Screen height in physical pixels
+	Shape  Shape    `json:"shape"`
+	DPR    float64  `json:"dpr"` // Device pixel ratio
+	JS     JSLevel  `json:"js"`
+	CSS    CSSLevel `json:"css"`
+}
+
+// ViewportWidth returns the screen width in CSS pixels
+func (d DeviceProfile) ViewportWidth() int {
+	return int(math.Round(float64(d.Width) / d.DPR))
+}
+
+// ViewportHeight returns the screen height in CSS pixels
+func (d DeviceProfile) ViewportHeight() int {
+	return int(math.Round(float64(d.Height) / d.DPR))
+}
+
+// IsRound reports whether the screen is round
+func (d DeviceProfile) IsRound() bool {
+	return d.Shape == ShapeRound
+}
+
+// SafeSize returns the side, in CSS pixels, of the largest centred square
+// that is fully visible on the screen
+func (d DeviceProfile) SafeSize() int {
+	size := d.ViewportWidth()
+	if height := d.ViewportHeight(); height < size {
+		size = height
+	}
+	if d.IsRound() {
+		return int(float64(size) / math.Sqrt2)
+	}
+	return size
+}
+
+// DeviceScreen is the screen description exposed to face scripts as WATCHFACE_DEVICE
+type DeviceScreen struct {
+	Width  int     `json:"width"`  // Viewport width in CSS pixels
+	Height int     `json:"height"` // Viewport height in CSS pixels
+	Shape  Shape   `json:"shape"`
+	DPR    float64 `json:"dpr"`
+}
+
+// Screen returns the screen description in CSS pixels
+func (d DeviceProfile) Screen() DeviceScreen {
+	return DeviceScreen{
+		Width:  d.ViewportWidth(),
+		Height: d.ViewportHeight(),
+		Shape:  d.Shape,
+		DPR:    d.DPR,
+	}
+}
+
+// deviceProfiles are the built-in device profiles
+var deviceProfiles = []DeviceProfile{
+	{ID: "round-360", Name: "Round 360px (entry level)", Width: 360, Height: 360, Shape: ShapeRound, DPR: 1, JS: ES2015, CSS: CSS3},
+	{ID: "round-454", Name: "Round 454px AMOLED", Width: 454, Height: 454, Shape: ShapeRound, DPR: 2, JS: ES2020, CSS: CSSModern},
+	{ID: "round-466", Name: "Round 466px AMOLED", Width: 466, Height: 466, Shape: ShapeRound, DPR: 2, JS: ES2020, CSS: CSSModern},
+	{ID: "square-320", Name: "Square 320px (legacy)", Width: 320, Height: 320, Shape: ShapeSquare, DPR: 1, JS: ES5, CSS: CSS3},
+	{ID: "rect-368x448", Name: "Rectangular 368x448", Width: 368, Height: 448, Shape: ShapeRect, DPR: 2, JS: ES2020, CSS: CSSModern},
+	{ID: "rect-396x484", Name: "Rectangular 396x484", Width: 396, Height: 484, Shape: ShapeRect, DPR: 2, JS: ES2020, CSS: CSSModern},
+}
+
+// DeviceProfiles returns the built-in device profiles
+func DeviceProfiles() []DeviceProfile {
+	return append([]DeviceProfile(nil), deviceProfiles...)
+}
+
+// LookupDevice returns the device profile with the given ID
+func LookupDevice(id string) (DeviceProfile, bool) {
+	for _, device := range deviceProfiles {
+		if device.ID == id {
+			return device, true
+		}
+	}
+	return DeviceProfile{}, false
+}
+
+// TemplateRequirements is implemented by templates that need a minimum WebView level
+type TemplateRequirements interface {
+	Requirements() (JSLevel, CSSLevel)
+}
+
+// checkDeviceSupport checks that a device can run a template
+func checkDeviceSupport(device DeviceProfile, t Template) error {
+	requirements, ok := t.(TemplateRequirements)
+	if !ok {
+		return nil
+	}
+	js, css := requirements.Requirements()
+	if device.JS < js {
+		return fmt.Errorf("template %s requires %s but device %s supports %s", t.ID(), js, device.ID, device.JS)
+	}
+	if device.CSS < css {
+		return fmt.Errorf("template %s requires %s but device %s supports %s", t.ID(), css, device.ID, device.CSS)
+	}
+	return nil
+}
+
+// TemplateData is the data templates are rendered against
+type TemplateData struct {
+	BuildOptions
+	Device *DeviceProfile // Primary target device, nil for a responsive layout
+	Theme  Theme          // Template's default theme with the options' overrides applied
+	Locale Locale         // Primary locale, which sets the document language
+}
+
+// NewTemplateData returns the template data for the given options. The signing
+// key is left out, so that templates cannot copy it into the package.
+func NewTemplateData(options BuildOptions) TemplateData {
+	options.SigningKey = nil
+	data := TemplateData{BuildOptions: options, Theme: options.ResolvedTheme(), Locale: options.PrimaryLocale()}
+	if device, ok := options.PrimaryDevice(); ok {
+		data.Device = &device
+	}
+	return data
+}
+
+// PrimaryDevice returns the first target device, which templates lay out for
+func (o BuildOptions) PrimaryDevice() (DeviceProfile, bool) {
+	if len(o.Devices) == 0 {
+		return DeviceProfile{}, false
+	}
+	return LookupDevice(o.Devices[0])
+}
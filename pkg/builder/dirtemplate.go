@@ -0,0 +1,228 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	// templateDescriptorFile is the descriptor of an on-disk template directory
+	templateDescriptorFile = "template.json"
+	// templateEntrypoint is the file every on-disk template directory must provide
+	templateEntrypoint = "index.html.tmpl"
+	// templateSuffix marks files rendered with text/template
+	templateSuffix = ".tmpl"
+)
+
+// TemplateDescriptor is the template.json file of an on-disk template directory
+type TemplateDescriptor struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Difficulty  string   `json:"difficulty,omitempty"`
+	Features    []string `json:"features,omitempty"`
+	JS          JSLevel  `json:"js,omitempty"`  // Minimum JS level, e.g. "es2015"
+	CSS         CSSLevel `json:"css,omitempty"` // Minimum CSS level, e.g. "css3"
+}
+
+// DirTemplate is a template loaded from a directory on disk.
+// Files ending in .tmpl are rendered with text/template against the
+// TemplateData and written without the suffix; all other files are
+// copied as assets, keeping their relative paths.
+type DirTemplate struct {
+	dir        string
+	descriptor TemplateDescriptor
+}
+
+// LoadTemplateDir loads a template from a directory containing template.json and index.html.tmpl
+func LoadTemplateDir(dir string) (*DirTemplate, error) {
+	data, err := os.ReadFile(filepath.Join(dir, templateDescriptorFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", templateDescriptorFile, err)
+	}
+
+	var descriptor TemplateDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in %s: %w", templateDescriptorFile, dir, err)
+	}
+	if descriptor.ID == "" {
+		descriptor.ID = filepath.Base(dir)
+	}
+	if descriptor.Name == "" {
+		descriptor.Name = descriptor.ID
+	}
+	if descriptor.Category == "" {
+		descriptor.Category = "custom"
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, templateEntrypoint)); err != nil {
+		return nil, fmt.Errorf("template %s has no %s: %w", descriptor.ID, templateEntrypoint, err)
+	}
+
+	return &DirTemplate{dir: dir, descriptor: descriptor}, nil
+}
+
+func (t *DirTemplate) ID() string          { return t.descriptor.ID }
+func (t *DirTemplate) Name() string        { return t.descriptor.Name }
+func (t *DirTemplate) Description() string { return t.descriptor.Description }
+func (t *DirTemplate) Category() string    { return t.descriptor.Category }
+func (t *DirTemplate) Difficulty() string  { return t.descriptor.Difficulty }
+func (t *DirTemplate) Features() []string  { return t.descriptor.Features }
+
+// Requirements returns the WebView level declared in template.json
+func (t *DirTemplate) Requirements() (JSLevel, CSSLevel) {
+	return t.descriptor.JS, t.descriptor.CSS
+}
+
+// Dir returns the directory the template was loaded from
+func (t *DirTemplate) Dir() string {
+	return t.dir
+}
+
+// Generate renders the template directory
+func (t *DirTemplate) Generate(options BuildOptions) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	err := filepath.WalkDir(t.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != t.dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(t.dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == templateDescriptorFile {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(relPath, templateSuffix) {
+			rendered, err := renderTextTemplate(relPath, content, options)
+			if err != nil {
+				return err
+			}
+			files[strings.TrimSuffix(relPath, templateSuffix)] = rendered
+			return nil
+		}
+
+		files[relPath] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", t.descriptor.ID, err)
+	}
+
+	return files, nil
+}
+
+// renderTextTemplate renders a text/template file against the template data
+func renderTextTemplate(name string, content []byte, options BuildOptions) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NewTemplateData(options)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DiscoverTemplates loads template directories from the given paths.
+// Each path may be a template directory itself or a directory whose
+// immediate sub-directories are template directories.
+func DiscoverTemplates(paths ...string) ([]*DirTemplate, error) {
+	var templates []*DirTemplate
+
+	for _, path := range paths {
+		if isTemplateDir(path) {
+			t, err := LoadTemplateDir(path)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, t)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template directory: %w", err)
+		}
+		for _, entry := range entries {
+			subDir := filepath.Join(path, entry.Name())
+			if !entry.IsDir() || !isTemplateDir(subDir) {
+				continue
+			}
+			t, err := LoadTemplateDir(subDir)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, t)
+		}
+	}
+
+	return templates, nil
+}
+
+// isTemplateDir reports whether dir contains a template descriptor
+func isTemplateDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, templateDescriptorFile))
+	return err == nil && !info.IsDir()
+}
+
+// UserTemplateDir returns the per-user directory searched for template directories
+func UserTemplateDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "watchface-builder", "templates"), nil
+}
+
+// SkippedTemplate is a template directory that LoadTemplateDirs did not register
+type SkippedTemplate struct {
+	Template *DirTemplate
+	Err      error
+}
+
+// LoadTemplateDirs discovers template directories and registers them. A template
+// whose ID is already taken, by a built-in template or one loaded before it, is
+// skipped and returned with the reason, so that callers can warn about it
+// without failing builds that do not use it. Directories that cannot be read
+// or loaded are still an error.
+func (r *Registry) LoadTemplateDirs(paths ...string) ([]SkippedTemplate, error) {
+	templates, err := DiscoverTemplates(paths...)
+	if err != nil {
+		return nil, err
+	}
+	var skipped []SkippedTemplate
+	for _, t := range templates {
+		if err := r.Register(t); err != nil {
+			skipped = append(skipped, SkippedTemplate{Template: t, Err: err})
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplateDir writes a template directory with the given files
+func writeTemplateDir(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, path, content)
+	}
+}
+
+func TestDirTemplateGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "retro")
+	writeTemplateDir(t, dir, map[string]string{
+		templateDescriptorFile: `{"id": "retro", "name": "Retro"}`,
+		templateEntrypoint:     `<title>{{.Name}}</title><p>{{.Version}} by {{.Author}}</p>`,
+		"style.css.tmpl":       `body { color: {{color .Theme.Foreground}}; }`,
+		"img/bg.png":           "png {{.Name}}",
+		".git/config":          "hidden",
+	})
+
+	tmpl, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplateDir() error = %v", err)
+	}
+	files, err := tmpl.Generate(BuildOptions{
+		Name:    "Tom & Jerry",
+		Version: "2.0.0",
+		Author:  "<Ada>",
+		Theme:   Theme{Foreground: "#123456"},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if want := []string{"img/bg.png", "index.html", "style.css"}; !equalStrings(sortedKeys(files), want) {
+		t.Errorf("files = %v, want %v", sortedKeys(files), want)
+	}
+	// text/template leaves option values unescaped
+	if got, want := string(files["index.html"]), "<title>Tom & Jerry</title><p>2.0.0 by <Ada></p>"; got != want {
+		t.Errorf("index.html = %q, want %q", got, want)
+	}
+	if got, want := string(files["style.css"]), "body { color: #123456; }"; got != want {
+		t.Errorf("style.css = %q, want %q", got, want)
+	}
+	if got, want := string(files["img/bg.png"]), "png {{.Name}}"; got != want {
+		t.Errorf("img/bg.png = %q, want %q", got, want)
+	}
+}
+
+func TestLoadTemplateDirDefaults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plain")
+	writeTemplateDir(t, dir, map[string]string{
+		templateDescriptorFile: `{}`,
+		templateEntrypoint:     `<html></html>`,
+	})
+
+	tmpl, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplateDir() error = %v", err)
+	}
+	if tmpl.ID() != "plain" || tmpl.Name() != "plain" || tmpl.Category() != "custom" || tmpl.Dir() != dir {
+		t.Errorf("template = %s %q %s %s", tmpl.ID(), tmpl.Name(), tmpl.Category(), tmpl.Dir())
+	}
+}
+
+func TestLoadTemplateDirRequiresEntrypoint(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "broken")
+	writeTemplateDir(t, dir, map[string]string{
+		templateDescriptorFile: `{"id": "broken"}`,
+		"index.html":           `<html></html>`,
+	})
+
+	if _, err := LoadTemplateDir(dir); err == nil || !strings.Contains(err.Error(), templateEntrypoint) {
+		t.Errorf("LoadTemplateDir() error = %v, want one naming %s", err, templateEntrypoint)
+	}
+	if _, err := NewRegistry().LoadTemplateDirs(root); err == nil {
+		t.Error("LoadTemplateDirs() loaded a template without an entrypoint")
+	}
+}
+
+func TestLoadTemplateDirsSkipsTakenIDs(t *testing.T) {
+	root := t.TempDir()
+	for name, id := range map[string]string{"again": "retro", "custom": "custom", "retro": "retro", "neon": "neon"} {
+		writeTemplateDir(t, filepath.Join(root, name), map[string]string{
+			templateDescriptorFile: `{"id": "` + id + `"}`,
+			templateEntrypoint:     `<html></html>`,
+		})
+	}
+	// Not a template directory, so not loaded
+	writeTemplateDir(t, filepath.Join(root, "notes"), map[string]string{"README": "notes"})
+
+	registry := NewRegistry()
+	if err := registry.Register(customTemplate{}); err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := registry.LoadTemplateDirs(root)
+	if err != nil {
+		t.Fatalf("LoadTemplateDirs() error = %v", err)
+	}
+
+	// Directories are loaded in name order, so again claims retro first
+	var skippedDirs []string
+	for _, s := range skipped {
+		skippedDirs = append(skippedDirs, filepath.Base(s.Template.Dir()))
+		if s.Err == nil || !strings.Contains(s.Err.Error(), s.Template.ID()) {
+			t.Errorf("%s skipped with error %v", s.Template.Dir(), s.Err)
+		}
+	}
+	if want := []string{"custom", "retro"}; !equalStrings(skippedDirs, want) {
+		t.Errorf("skipped = %v, want %v", skippedDirs, want)
+	}
+	if want := []string{"custom", "retro", "neon"}; !equalStrings(registry.IDs(), want) {
+		t.Errorf("IDs() = %v, want %v", registry.IDs(), want)
+	}
+	if tmpl, _ := registry.Lookup("custom"); tmpl != (customTemplate{}) {
+		t.Errorf("custom = %T, want the built-in template", tmpl)
+	}
+	if tmpl, _ := registry.Lookup("retro"); filepath.Base(tmpl.(*DirTemplate).Dir()) != "again" {
+		t.Errorf("retro was loaded from %s", tmpl.(*DirTemplate).Dir())
+	}
+}
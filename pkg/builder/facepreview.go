@@ -0,0 +1,249 @@
+package builder
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+// Colours the built-in templates do not take from the theme, kept in sync with
+// their style.css
+var (
+	simpleShadow = color.NRGBA{0, 0, 0, 77}
+	analogShadow = color.NRGBA{0, 0, 0, 10}
+)
+
+// renderAnalogPreview draws the analog template at a fixed time, following
+// templates/analog/script.js. Sizes are in CSS pixels and scaled to the context;
+// gg does not apply the matrix to line widths, so those are scaled explicitly.
+func renderAnalogPreview(dc *gg.Context, options BuildOptions, font *truetype.Font, scale float64, at time.Time) error {
+	if err := loadPreviewFonts(); err != nil {
+		return err
+	}
+
+	theme := options.ResolvedTheme()
+	foreground := themeColor(theme.Foreground, color.Black)
+	accent := themeColor(theme.Accent, color.Black)
+	background1, background2 := previewBackground(options)
+	drawLinearGradient(dc, 135, background1, background2)
+
+	width, height := float64(dc.Width())/scale, float64(dc.Height())/scale
+	round := false
+	if device, ok := options.PrimaryDevice(); ok {
+		round = device.IsRound()
+	}
+
+	size := math.Min(width, height)
+	margin := 4.0
+	if !round {
+		size *= 0.9
+		margin = 20
+	}
+	cx, cy := width/2, height/2
+	radius := size/2 - margin
+
+	dc.Push()
+	defer dc.Pop()
+	dc.Scale(scale, scale)
+
+	// The canvas casts a soft shadow outside its circle, except on round screens
+	if !round {
+		// Stacked translucent discs approximate the 30px blur
+		for i := 0; i < 6; i++ {
+			dc.DrawCircle(cx, cy+10, size/2+10-float64(i)*2.5)
+			dc.SetColor(analogShadow)
+			dc.Fill()
+		}
+		dc.Push()
+		dc.DrawCircle(cx, cy, size/2)
+		dc.Clip()
+		dc.Identity()
+		drawLinearGradient(dc, 135, background1, background2)
+		dc.Pop()
+	}
+
+	// Dial
+	dc.DrawCircle(cx, cy, radius)
+	dc.SetColor(themeColor(theme.Dial, color.White))
+	dc.FillPreserve()
+	dc.SetColor(foreground)
+	dc.SetLineWidth(2 * scale)
+	dc.Stroke()
+
+	// Hour markers
+	dc.SetColor(foreground)
+	dc.SetLineCap(gg.LineCapButt)
+	dc.SetLineWidth(3 * scale)
+	dc.SetFontFace(truetype.NewFace(previewFont(options, font, false), &truetype.Options{Size: radius * 0.16 * scale}))
+	for i := 0; i < 12; i++ {
+		angle := gg.Radians(float64(i*30 - 90))
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		switch theme.Markers {
+		case MarkersLines:
+			dc.DrawLine(cx+cos*(radius-15), cy+sin*(radius-15), cx+cos*(radius-5), cy+sin*(radius-5))
+			dc.Stroke()
+		case MarkersDots:
+			dc.DrawCircle(cx+cos*(radius-10), cy+sin*(radius-10), 3)
+			dc.Fill()
+		case MarkersNumerals:
+			numeral := strconv.Itoa(i)
+			if i == 0 {
+				numeral = "12"
+			}
+			dc.DrawStringAnchored(numeral, cx+cos*radius*0.82, cy+sin*radius*0.82, 0.5, 0.35)
+		}
+	}
+
+	hours := float64(at.Hour() % 12)
+	minutes := float64(at.Minute())
+	seconds := float64(at.Second())
+
+	drawAnalogHand(dc, cx, cy, (hours+minutes/60)*30, radius, scale, theme.HourHand, foreground)
+	drawAnalogHand(dc, cx, cy, (minutes+seconds/60)*6, radius, scale, theme.MinuteHand, foreground)
+	drawAnalogHand(dc, cx, cy, seconds*6, radius, scale, theme.SecondHand, accent)
+
+	// Centre dot
+	dc.DrawCircle(cx, cy, 8)
+	dc.SetColor(accent)
+	dc.Fill()
+	return nil
+}
+
+// drawAnalogHand draws a hand from the centre, with degrees measured clockwise from 12
+// and the hand's width in CSS pixels scaled to the context
+func drawAnalogHand(dc *gg.Context, cx, cy, degrees, radius, scale float64, hand HandStyle, defaultColor color.Color) {
+	angle := gg.Radians(degrees - 90)
+	length := radius * hand.Length
+	dc.DrawLine(cx, cy, cx+math.Cos(angle)*length, cy+math.Sin(angle)*length)
+	dc.SetColor(themeColor(hand.Color, defaultColor))
+	dc.SetLineWidth(hand.Width * scale)
+	dc.SetLineCap(gg.LineCapRound)
+	dc.Stroke()
+}
+
+// previewClock returns the time of day as the face's scripts show it: the hours
+// as the locale formats them and, on 12-hour clocks, the day period. The bundled
+// fonts have no CJK or Hangul glyphs, so a period the font cannot draw is left out.
+func previewClock(l Locale, at time.Time, f *truetype.Font) string {
+	clock := fmt.Sprintf("%s:%02d:%02d", l.Hours(at.Hour()), at.Minute(), at.Second())
+	period := l.DayPeriod(at.Hour())
+	switch {
+	case period == "" || !hasGlyphs(f, period):
+		return clock
+	case l.PeriodFirst():
+		return period + " " + clock
+	}
+	return clock + " " + period
+}
+
+// faceTextSizes returns the CSS pixel sizes of the time and date lines and the gap
+// between them: the device rule from index.html.tmpl as percentages of the safe
+// size when a device is targeted, otherwise the rem sizes from style.css
+func faceTextSizes(options BuildOptions, width float64, device, desktop, small [3]float64) [3]float64 {
+	if profile, ok := options.PrimaryDevice(); ok {
+		safe := profile.SafeSize()
+		return [3]float64{
+			float64(percent(safe, device[0])),
+			float64(percent(safe, device[1])),
+			float64(percent(safe, device[2])),
+		}
+	}
+	if width <= 480 {
+		return small
+	}
+	return desktop
+}
+
+// renderSimplePreview draws the simple template at a fixed time, following
+// templates/simple
+func renderSimplePreview(dc *gg.Context, options BuildOptions, font *truetype.Font, scale float64, at time.Time) error {
+	if err := loadPreviewFonts(); err != nil {
+		return err
+	}
+
+	theme := options.ResolvedTheme()
+	locale := options.PrimaryLocale()
+	foreground := themeColor(theme.Foreground, color.White)
+	timeFont := previewFont(options, font, false)
+	background1, background2 := previewBackground(options)
+	drawLinearGradient(dc, 135, background1, background2)
+
+	clock := locale.FormatTime(at, true)
+	if !hasGlyphs(timeFont, clock) {
+		clock = previewClock(locale, at, timeFont)
+	}
+
+	sizes := faceTextSizes(options, float64(dc.Width())/scale,
+		[3]float64{20, 8, 4}, [3]float64{64, 24, 16}, [3]float64{48, 19.2, 16})
+	drawPreviewLines(dc, []previewLine{
+		{
+			text:          clock,
+			font:          timeFont,
+			size:          sizes[0] * scale,
+			letterSpacing: 0.1 * sizes[0] * scale,
+			color:         foreground,
+			shadows:       []textShadow{{dy: 2 * scale, blur: 10 * scale, color: simpleShadow}},
+		},
+		{
+			text:      locale.FormatDate(at),
+			font:      previewFont(options, font, false),
+			size:      sizes[1] * scale,
+			marginTop: sizes[2] * scale,
+			color:     withOpacity(foreground, 0.9),
+		},
+	})
+	return nil
+}
+
+// renderDigitalPreview draws the digital template at a fixed time, following
+// templates/digital
+func renderDigitalPreview(dc *gg.Context, options BuildOptions, font *truetype.Font, scale float64, at time.Time) error {
+	if err := loadPreviewFonts(); err != nil {
+		return err
+	}
+
+	theme := options.ResolvedTheme()
+	locale := options.PrimaryLocale()
+	neon := themeColor(theme.Foreground, color.White)
+	timeFont := previewFont(options, font, true)
+	dateFont := previewFont(options, font, false)
+	background1, background2 := previewBackground(options)
+	drawLinearGradient(dc, 135, background1, background2)
+
+	// The bundled fonts have no CJK glyphs, so the weekday is left out rather
+	// than drawn as boxes
+	date := locale.FormatDate(at)
+	if weekday := locale.Weekdays[at.Weekday()]; hasGlyphs(dateFont, weekday) {
+		date += " " + weekday
+	}
+
+	sizes := faceTextSizes(options, float64(dc.Width())/scale,
+		[3]float64{16, 7, 6}, [3]float64{80, 24, 32}, [3]float64{48, 19.2, 32})
+	var glow []textShadow
+	for _, blur := range []float64{10, 20, 30} {
+		glow = append(glow, textShadow{blur: blur * scale, color: neon})
+	}
+	drawPreviewLines(dc, []previewLine{
+		{
+			text:          previewClock(locale, at, timeFont),
+			font:          timeFont,
+			size:          sizes[0] * scale,
+			letterSpacing: 0.1 * sizes[0] * scale,
+			color:         neon,
+			shadows:       glow,
+		},
+		{
+			text:      date,
+			font:      dateFont,
+			size:      sizes[1] * scale,
+			marginTop: sizes[2] * scale,
+			color:     withOpacity(themeColor(theme.Accent, neon), 0.8),
+		},
+	})
+	return nil
+}
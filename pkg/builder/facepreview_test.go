@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"image"
+	"testing"
+
+	"github.com/fogleman/gg"
+)
+
+// handCoverage renders the analog face at a scale and returns the number of
+// pixels covered by the red hour hand, counting anti-aliased edges fractionally
+func handCoverage(t *testing.T, scale float64) float64 {
+	t.Helper()
+	options := BuildOptions{
+		Template: "analog",
+		Theme: Theme{
+			Background: [2]string{"#ffffff", "#ffffff"},
+			Foreground: "#0000ff",
+			Accent:     "#00ff00",
+			Dial:       "#ffffff",
+			Markers:    MarkersNone,
+			HourHand:   HandStyle{Color: "#ff0000"},
+		},
+	}
+	size := int(200 * scale)
+	dc := gg.NewContext(size, size)
+	if err := renderAnalogPreview(dc, options, nil, scale, DefaultPreviewTime); err != nil {
+		t.Fatal(err)
+	}
+
+	img := dc.Image().(*image.RGBA)
+	coverage := 0.0
+	for i := 0; i < len(img.Pix); i += 4 {
+		r, g, b := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+		// Red blended over white keeps full red and equal green and blue
+		if r >= 250 && g == b {
+			coverage += float64(255-g) / 255
+		}
+	}
+	return coverage
+}
+
+func TestAnalogHandsScaleWithDPR(t *testing.T) {
+	single := handCoverage(t, 1)
+	double := handCoverage(t, 2)
+	if single == 0 {
+		t.Fatal("hour hand not drawn")
+	}
+	// Twice the length and twice the width cover four times the pixels
+	if ratio := double / single; ratio < 3.6 || ratio > 4.4 {
+		t.Errorf("hour hand covers %.0f pixels at DPR 2 and %.0f at DPR 1, ratio %.2f, want about 4",
+			double, single, ratio)
+	}
+}
@@ -0,0 +1,122 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/net/html"
+)
+
+// Files and family name of a font bundled with BuildOptions.Font
+const (
+	FontFamilyName = "WatchfaceFont"
+	FontFile       = "fonts/watchface.ttf"
+	FontCSSFile    = "font.css"
+)
+
+// fontBaseChars are always kept in a subset font: digits and the punctuation of
+// times, dates and percentages
+const fontBaseChars = "0123456789:-/.,%+ "
+
+// parseFont checks that a font is TrueType the builder can subset and draw
+func parseFont(ttf []byte) (*truetype.Font, error) {
+	font, err := truetype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported font (expected TrueType): %v", err)
+	}
+	tables, err := readTables(ttf)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tables["glyf"]; !ok {
+		return nil, fmt.Errorf("unsupported font: only TrueType outlines are supported")
+	}
+	return font, nil
+}
+
+// addFont bundles the font, subset to the characters the face uses, and links
+// an @font-face rule for it from index.html
+func addFont(files map[string][]byte, ttf []byte) error {
+	subset, err := SubsetFont(ttf, fontChars(files))
+	if err != nil {
+		return err
+	}
+	files[FontFile] = subset
+	files[FontCSSFile] = []byte(fmt.Sprintf(
+		"@font-face {\n    font-family: '%s';\n    src: url(\"%s\") format(\"truetype\");\n}\n", FontFamilyName, FontFile))
+
+	if index, ok := files["index.html"]; ok {
+		files["index.html"] = insertBeforeClosing(index, "</head", []byte("<link rel=\"stylesheet\" href=\""+FontCSSFile+"\">\n"))
+	}
+	return nil
+}
+
+// fontChars returns the characters a face can show: the base characters, the
+// text of its HTML files and the string literals of its scripts and stylesheets.
+// Text built character by character at runtime is not found.
+func fontChars(files map[string][]byte) []rune {
+	seen := map[rune]bool{}
+	add := func(text []byte) {
+		for _, c := range string(text) {
+			seen[c] = true
+		}
+	}
+	add([]byte(fontBaseChars))
+
+	for name, content := range files {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".html", ".htm":
+			htmlText(content, add)
+		case ".js", ".css":
+			stringLiterals(content, add)
+		}
+	}
+
+	chars := make([]rune, 0, len(seen))
+	for c := range seen {
+		if c >= ' ' {
+			chars = append(chars, c)
+		}
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	return chars
+}
+
+// htmlText passes the text of a document to fn, and the string literals of its
+// inline scripts and styles
+func htmlText(document []byte, fn func([]byte)) {
+	z := html.NewTokenizer(bytes.NewReader(document))
+	raw := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			raw = string(name) == "script" || string(name) == "style"
+		case html.EndTagToken:
+			raw = false
+		case html.TextToken:
+			if raw {
+				stringLiterals(z.Raw(), fn)
+			} else {
+				fn(z.Text())
+			}
+		}
+	}
+}
+
+// stringLiterals passes the contents of the quoted strings in code to fn
+func stringLiterals(code []byte, fn func([]byte)) {
+	for i := 0; i < len(code); i++ {
+		if c := code[i]; c == '"' || c == '\'' || c == '`' {
+			end := skipQuoted(code, i)
+			fn(code[i+1 : max(i+1, end-1)])
+			i = end - 1
+		}
+	}
+}
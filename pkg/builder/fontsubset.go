@@ -0,0 +1,370 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// subsetTables are the tables kept in a subset font. Layout tables such as GSUB,
+// GPOS and kern refer to glyph IDs that no longer exist and are dropped.
+var subsetTables = []string{"OS/2", "cmap", "cvt ", "fpgm", "gasp", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post", "prep"}
+
+// Composite glyph flags
+const (
+	argsAreWords    = 0x0001
+	haveScale       = 0x0008
+	moreComponents  = 0x0020
+	haveXYScale     = 0x0040
+	haveTwoByTwo    = 0x0080
+	compositeHeader = 10 // numberOfContours and the bounding box
+)
+
+// SubsetFont returns a TrueType font with only the glyphs of the given
+// characters, and the glyphs their composites are built from. Glyphs are
+// renumbered, so hinting survives but kerning and ligatures do not.
+func SubsetFont(ttf []byte, chars []rune) ([]byte, error) {
+	parsed, err := truetype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported font: %w", err)
+	}
+	tables, err := readTables(ttf)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"glyf", "loca", "head", "hhea", "hmtx", "maxp"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("unsupported font: no %s table (only TrueType outlines can be subset)", tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, fmt.Errorf("invalid font: truncated header tables")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	glyphs, err := splitGlyphs(tables["glyf"], tables["loca"], numGlyphs, binary.BigEndian.Uint16(head[50:]) == 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Characters and the glyphs they map to, with .notdef always kept as glyph 0
+	mapping := map[rune]uint16{}
+	keep := map[uint16]bool{0: true}
+	for _, c := range chars {
+		if c > 0xFFFF {
+			continue // Only the basic multilingual plane is mapped
+		}
+		if index := uint16(parsed.Index(c)); index != 0 {
+			mapping[c] = index
+			keep[index] = true
+		}
+	}
+	pending := make([]uint16, 0, len(keep))
+	for index := range keep {
+		pending = append(pending, index)
+	}
+	for len(pending) > 0 {
+		index := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, component := range compositeComponents(glyphs[index]) {
+			if int(component) < numGlyphs && !keep[component] {
+				keep[component] = true
+				pending = append(pending, component)
+			}
+		}
+	}
+
+	order := make([]uint16, 0, len(keep))
+	for index := range keep {
+		order = append(order, index)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	renumber := map[uint16]uint16{}
+	for newIndex, oldIndex := range order {
+		renumber[oldIndex] = uint16(newIndex)
+	}
+
+	// glyf and long loca
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(len(order)+1))
+	for i, oldIndex := range order {
+		binary.BigEndian.PutUint32(loca[4*i:], uint32(glyf.Len()))
+		glyf.Write(renumberComponents(glyphs[oldIndex], renumber))
+		for glyf.Len()%4 != 0 {
+			glyf.WriteByte(0)
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*len(order):], uint32(glyf.Len()))
+
+	// hmtx with a full metric per glyph
+	hmtx := tables["hmtx"]
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	metrics := make([]byte, 4*len(order))
+	for i, oldIndex := range order {
+		advance, lsb, err := horizontalMetric(hmtx, numberOfHMetrics, int(oldIndex))
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint16(metrics[4*i:], advance)
+		binary.BigEndian.PutUint16(metrics[4*i+2:], lsb)
+	}
+
+	newMapping := map[rune]uint16{}
+	for c, index := range mapping {
+		newMapping[c] = renumber[index]
+	}
+
+	out := map[string][]byte{
+		"glyf": glyf.Bytes(),
+		"loca": loca,
+		"hmtx": metrics,
+		"cmap": buildCmap(newMapping),
+		"head": patchUint16(head, 50, 1),
+		"hhea": patchUint16(hhea, 34, uint16(len(order))),
+		"maxp": patchUint16(maxp, 4, uint16(len(order))),
+	}
+	binary.BigEndian.PutUint32(out["head"][8:], 0)
+	if post, ok := tables["post"]; ok && len(post) >= 32 {
+		// Version 3 carries no glyph names
+		out["post"] = patchUint16(patchUint16(post[:32], 0, 3), 2, 0)
+	}
+	for _, tag := range subsetTables {
+		if _, ok := out[tag]; !ok {
+			if table, ok := tables[tag]; ok {
+				out[tag] = table
+			}
+		}
+	}
+	return writeFont(out), nil
+}
+
+// readTables returns the tables of a TrueType font by tag
+func readTables(ttf []byte) (map[string][]byte, error) {
+	if len(ttf) < 12 {
+		return nil, fmt.Errorf("invalid font: too short")
+	}
+	numTables := int(binary.BigEndian.Uint16(ttf[4:]))
+	if len(ttf) < 12+16*numTables {
+		return nil, fmt.Errorf("invalid font: truncated table directory")
+	}
+	tables := map[string][]byte{}
+	for i := 0; i < numTables; i++ {
+		record := ttf[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(record[8:]), binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(ttf)) {
+			return nil, fmt.Errorf("invalid font: table %q out of bounds", record[:4])
+		}
+		tables[string(record[:4])] = ttf[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// splitGlyphs returns the outline data of every glyph
+func splitGlyphs(glyf, loca []byte, numGlyphs int, longOffsets bool) ([][]byte, error) {
+	offset := func(i int) int {
+		if longOffsets {
+			return int(binary.BigEndian.Uint32(loca[4*i:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+	}
+	size := 2
+	if longOffsets {
+		size = 4
+	}
+	if len(loca) < size*(numGlyphs+1) {
+		return nil, fmt.Errorf("invalid font: truncated loca table")
+	}
+
+	glyphs := make([][]byte, numGlyphs)
+	for i := range glyphs {
+		start, end := offset(i), offset(i+1)
+		if start > end || end > len(glyf) {
+			return nil, fmt.Errorf("invalid font: glyph %d out of bounds", i)
+		}
+		glyphs[i] = glyf[start:end]
+	}
+	return glyphs, nil
+}
+
+// compositeComponents returns the glyphs a composite glyph is built from
+func compositeComponents(glyph []byte) []uint16 {
+	var components []uint16
+	walkComponents(glyph, func(at int) {
+		components = append(components, binary.BigEndian.Uint16(glyph[at:]))
+	})
+	return components
+}
+
+// renumberComponents returns a copy of a glyph with its component glyph IDs renumbered
+func renumberComponents(glyph []byte, renumber map[uint16]uint16) []byte {
+	glyph = append([]byte(nil), glyph...)
+	walkComponents(glyph, func(at int) {
+		binary.BigEndian.PutUint16(glyph[at:], renumber[binary.BigEndian.Uint16(glyph[at:])])
+	})
+	return glyph
+}
+
+// walkComponents calls fn with the offset of each component glyph ID of a composite glyph
+func walkComponents(glyph []byte, fn func(at int)) {
+	if len(glyph) < compositeHeader || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return
+	}
+	for at := compositeHeader; at+4 <= len(glyph); {
+		flags := binary.BigEndian.Uint16(glyph[at:])
+		fn(at + 2)
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&haveTwoByTwo != 0:
+			at += 8
+		}
+		if flags&moreComponents == 0 {
+			return
+		}
+	}
+}
+
+// horizontalMetric returns the advance width and left side bearing of a glyph
+func horizontalMetric(hmtx []byte, numberOfHMetrics, index int) (uint16, uint16, error) {
+	if numberOfHMetrics == 0 || len(hmtx) < 4*numberOfHMetrics {
+		return 0, 0, fmt.Errorf("invalid font: truncated hmtx table")
+	}
+	if index < numberOfHMetrics {
+		return binary.BigEndian.Uint16(hmtx[4*index:]), binary.BigEndian.Uint16(hmtx[4*index+2:]), nil
+	}
+	// Glyphs past the long metrics share the last advance width
+	advance := binary.BigEndian.Uint16(hmtx[4*(numberOfHMetrics-1):])
+	at := 4*numberOfHMetrics + 2*(index-numberOfHMetrics)
+	if at+2 > len(hmtx) {
+		return advance, 0, nil
+	}
+	return advance, binary.BigEndian.Uint16(hmtx[at:]), nil
+}
+
+// buildCmap returns a cmap table with a format 4 subtable for the Unicode and
+// Windows platforms
+func buildCmap(mapping map[rune]uint16) []byte {
+	chars := make([]rune, 0, len(mapping))
+	for c := range mapping {
+		chars = append(chars, c)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	// Runs of consecutive characters mapping to consecutive glyphs share a segment
+	type segment struct{ start, end, delta uint16 }
+	var segments []segment
+	for _, c := range chars {
+		code, delta := uint16(c), mapping[c]-uint16(c)
+		if n := len(segments); n > 0 && segments[n-1].end+1 == code && segments[n-1].delta == delta {
+			segments[n-1].end = code
+			continue
+		}
+		segments = append(segments, segment{code, code, delta})
+	}
+	segments = append(segments, segment{0xFFFF, 0xFFFF, 1})
+
+	segCount := len(segments)
+	searchRange, entrySelector := 2, 0
+	for searchRange*2 <= 2*segCount {
+		searchRange *= 2
+		entrySelector++
+	}
+
+	subtable := make([]byte, 16+8*segCount)
+	put := func(at int, v uint16) { binary.BigEndian.PutUint16(subtable[at:], v) }
+	put(0, 4)
+	put(2, uint16(len(subtable)))
+	put(6, uint16(2*segCount))
+	put(8, uint16(searchRange))
+	put(10, uint16(entrySelector))
+	put(12, uint16(2*segCount-searchRange))
+	for i, s := range segments {
+		put(14+2*i, s.end)
+		put(16+2*segCount+2*i, s.start)
+		put(16+4*segCount+2*i, s.delta)
+	}
+
+	cmap := make([]byte, 20, 20+len(subtable))
+	binary.BigEndian.PutUint16(cmap[2:], 2)
+	for i, platform := range [][2]uint16{{0, 3}, {3, 1}} {
+		record := cmap[4+8*i:]
+		binary.BigEndian.PutUint16(record, platform[0])
+		binary.BigEndian.PutUint16(record[2:], platform[1])
+		binary.BigEndian.PutUint32(record[4:], 20)
+	}
+	return append(cmap, subtable...)
+}
+
+// patchUint16 returns a copy of a table with a 16-bit field replaced
+func patchUint16(table []byte, at int, v uint16) []byte {
+	table = append([]byte(nil), table...)
+	binary.BigEndian.PutUint16(table[at:], v)
+	return table
+}
+
+// writeFont assembles tables into a font file, with checksums and the head
+// checksum adjustment filled in
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= numTables {
+		searchRange *= 2
+		entrySelector++
+	}
+	searchRange *= 16
+
+	font := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(font, 0x00010000)
+	binary.BigEndian.PutUint16(font[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(font[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(font[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(font[10:], uint16(16*numTables-searchRange))
+
+	headOffset := 0
+	for i, tag := range tags {
+		table := tables[tag]
+		record := font[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], tableChecksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(font)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		if tag == "head" {
+			headOffset = len(font)
+		}
+		font = append(font, table...)
+		for len(font)%4 != 0 {
+			font = append(font, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(font[headOffset+8:], 0xB1B0AFBA-tableChecksum(font))
+	return font
+}
+
+// tableChecksum sums a table as big-endian 32-bit words, zero padded
+func tableChecksum(table []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(table); i += 4 {
+		var word [4]byte
+		copy(word[:], table[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
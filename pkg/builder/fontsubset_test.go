@@ -0,0 +1,191 @@
+package builder
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontGlyphs returns the outline data of every glyph of a font
+func fontGlyphs(t *testing.T, ttf []byte) [][]byte {
+	t.Helper()
+	tables, err := readTables(ttf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	longOffsets := binary.BigEndian.Uint16(tables["head"][50:]) == 1
+	glyphs, err := splitGlyphs(tables["glyf"], tables["loca"], numGlyphs, longOffsets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return glyphs
+}
+
+// withComposite returns Go Regular, which has no composite glyphs, with printable
+// ASCII and an extra glyph for c built from the glyphs of base and mark, the
+// mark shifted by dx and dy font units
+func withComposite(t *testing.T, c, base, mark rune, dx, dy int16) []byte {
+	t.Helper()
+	original, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := readTables(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyphs := fontGlyphs(t, goregular.TTF)
+	baseIndex, markIndex := uint16(original.Index(base)), uint16(original.Index(mark))
+
+	composite := make([]byte, compositeHeader, compositeHeader+16)
+	binary.BigEndian.PutUint16(composite, 0xFFFF) // numberOfContours -1
+	copy(composite[2:], glyphs[baseIndex][2:compositeHeader])
+	const argsAreXYValues = 0x0002
+	for _, component := range []struct {
+		flags  uint16
+		index  uint16
+		dx, dy int16
+	}{
+		{argsAreWords | argsAreXYValues | moreComponents, baseIndex, 0, 0},
+		{argsAreWords | argsAreXYValues, markIndex, dx, dy},
+	} {
+		composite = binary.BigEndian.AppendUint16(composite, component.flags)
+		composite = binary.BigEndian.AppendUint16(composite, component.index)
+		composite = binary.BigEndian.AppendUint16(composite, uint16(component.dx))
+		composite = binary.BigEndian.AppendUint16(composite, uint16(component.dy))
+	}
+	glyphs = append(glyphs, composite)
+	newIndex := uint16(len(glyphs) - 1)
+
+	var glyf []byte
+	loca := make([]byte, 4*(len(glyphs)+1))
+	hmtx := make([]byte, 4*len(glyphs))
+	numberOfHMetrics := int(binary.BigEndian.Uint16(tables["hhea"][34:]))
+	for i, glyph := range glyphs {
+		binary.BigEndian.PutUint32(loca[4*i:], uint32(len(glyf)))
+		glyf = append(glyf, glyph...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		index := i
+		if i == int(newIndex) {
+			index = int(baseIndex)
+		}
+		advance, lsb, err := horizontalMetric(tables["hmtx"], numberOfHMetrics, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		binary.BigEndian.PutUint16(hmtx[4*i:], advance)
+		binary.BigEndian.PutUint16(hmtx[4*i+2:], lsb)
+	}
+	binary.BigEndian.PutUint32(loca[4*len(glyphs):], uint32(len(glyf)))
+
+	mapping := map[rune]uint16{c: newIndex}
+	for r := rune(' '); r <= '~'; r++ {
+		mapping[r] = uint16(original.Index(r))
+	}
+	tables["glyf"], tables["loca"], tables["hmtx"] = glyf, loca, hmtx
+	tables["cmap"] = buildCmap(mapping)
+	tables["head"] = patchUint16(tables["head"], 50, 1)
+	tables["hhea"] = patchUint16(tables["hhea"], 34, uint16(len(glyphs)))
+	tables["maxp"] = patchUint16(tables["maxp"], 4, uint16(len(glyphs)))
+	binary.BigEndian.PutUint32(tables["head"][8:], 0)
+	for _, tag := range []string{"GPOS", "GSUB", "GDEF", "kern"} {
+		delete(tables, tag)
+	}
+	return writeFont(tables)
+}
+
+func TestSubsetFont(t *testing.T) {
+	// é is drawn as an e with a full stop above it
+	const composite = 'é'
+	ttf := withComposite(t, composite, 'e', '.', 80, 700)
+	original, err := truetype.Parse(ttf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := []rune{'A', 'g', '1', ':', ' ', composite}
+
+	subset, err := SubsetFont(ttf, kept)
+	if err != nil {
+		t.Fatalf("SubsetFont() error = %v", err)
+	}
+	if len(subset) >= len(ttf)/4 {
+		t.Errorf("subset is %d bytes, the original %d", len(subset), len(ttf))
+	}
+	parsed, err := truetype.Parse(subset)
+	if err != nil {
+		t.Fatalf("subset does not parse: %v", err)
+	}
+
+	scale := fixed.Int26_6(original.FUnitsPerEm()) // Metrics in font units
+	for _, c := range kept {
+		index := parsed.Index(c)
+		if index == 0 {
+			t.Errorf("%q maps to .notdef", c)
+			continue
+		}
+		want := original.HMetric(scale, original.Index(c)).AdvanceWidth
+		if got := parsed.HMetric(scale, index).AdvanceWidth; got != want {
+			t.Errorf("advance of %q = %d, want %d", c, got, want)
+		}
+
+		// Outlines, including those assembled from components, are unchanged
+		var originalBuf, subsetBuf truetype.GlyphBuf
+		if err := originalBuf.Load(original, scale, original.Index(c), font.HintingNone); err != nil {
+			t.Fatal(err)
+		}
+		if err := subsetBuf.Load(parsed, scale, index, font.HintingNone); err != nil {
+			t.Fatalf("loading %q: %v", c, err)
+		}
+		if subsetBuf.Bounds != originalBuf.Bounds || len(subsetBuf.Points) != len(originalBuf.Points) {
+			t.Errorf("outline of %q: bounds %v with %d points, want %v with %d",
+				c, subsetBuf.Bounds, len(subsetBuf.Points), originalBuf.Bounds, len(originalBuf.Points))
+		}
+	}
+	for _, c := range "BZex9.€" {
+		if index := parsed.Index(c); index != 0 {
+			t.Errorf("%q maps to glyph %d, want 0", c, index)
+		}
+	}
+
+	// The components of é are kept, though e and the full stop are not mapped,
+	// and renumbered to glyphs of the subset
+	glyphs := fontGlyphs(t, subset)
+	if want := 1 + len(kept) + 2; len(glyphs) != want {
+		t.Errorf("subset has %d glyphs, want %d", len(glyphs), want)
+	}
+	components := compositeComponents(glyphs[parsed.Index(composite)])
+	if len(components) != 2 {
+		t.Fatalf("%q has components %v, want 2", composite, components)
+	}
+	for _, component := range components {
+		if int(component) >= len(glyphs) || component == 0 {
+			t.Errorf("%q refers to glyph %d of %d", composite, component, len(glyphs))
+		}
+	}
+
+	// The whole font sums to the magic number once the adjustment is applied,
+	// and every other table matches its directory checksum
+	if sum := tableChecksum(subset); sum != 0xB1B0AFBA {
+		t.Errorf("font checksum = %#x, want 0xb1b0afba", sum)
+	}
+	numTables := int(binary.BigEndian.Uint16(subset[4:]))
+	for i := 0; i < numTables; i++ {
+		record := subset[12+16*i:]
+		tag := string(record[:4])
+		offset, length := binary.BigEndian.Uint32(record[8:]), binary.BigEndian.Uint32(record[12:])
+		table := append([]byte(nil), subset[offset:offset+length]...)
+		if tag == "head" {
+			binary.BigEndian.PutUint32(table[8:], 0) // Summed without the adjustment
+		}
+		if got, want := tableChecksum(table), binary.BigEndian.Uint32(record[4:]); got != want {
+			t.Errorf("%s checksum = %#x, directory says %#x", tag, got, want)
+		}
+	}
+}
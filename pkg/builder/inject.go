@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// InjectScripts inserts script tags at the start of an HTML document's head, so
+// that they run before any of the face's own scripts. Documents without a head get
+// them after the html tag, or at the very start.
+func InjectScripts(document []byte, sources ...string) []byte {
+	var tags bytes.Buffer
+	for _, src := range sources {
+		fmt.Fprintf(&tags, "\n<script src=\"%s\"></script>", html.EscapeString(src))
+	}
+
+	at := 0
+	lower := bytes.ToLower(document)
+	for _, tag := range []string{"<head", "<html"} {
+		if start := findTag(lower, tag); start >= 0 {
+			if end := bytes.IndexByte(lower[start:], '>'); end >= 0 {
+				at = start + end + 1
+				break
+			}
+		}
+	}
+
+	injected := make([]byte, 0, len(document)+tags.Len())
+	injected = append(injected, document[:at]...)
+	injected = append(injected, tags.Bytes()...)
+	return append(injected, document[at:]...)
+}
+
+// findTag returns the offset of the first start tag named like tag ("<head"), not
+// matching longer names such as <header>
+func findTag(document []byte, tag string) int {
+	offset := 0
+	for {
+		i := bytes.Index(document[offset:], []byte(tag))
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		next := i + len(tag)
+		if next >= len(document) || bytes.IndexByte([]byte(" \t\r\n/>"), document[next]) >= 0 {
+			return i
+		}
+		offset = next
+	}
+}
+
+// insertBeforeClosing inserts snippet before the last closing tag named like tag
+// ("</body"), or appends it when the document has no such tag
+func insertBeforeClosing(document []byte, tag string, snippet []byte) []byte {
+	at := bytes.LastIndex(bytes.ToLower(document), []byte(tag))
+	if at < 0 {
+		at = len(document)
+	}
+
+	inserted := make([]byte, 0, len(document)+len(snippet))
+	inserted = append(inserted, document[:at]...)
+	inserted = append(inserted, snippet...)
+	return append(inserted, document[at:]...)
+}
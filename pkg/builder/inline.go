@@ -0,0 +1,305 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// InlineImageLimit is the size above which images stay separate files in inline mode
+const InlineImageLimit = 32 << 10
+
+// LayoutInline is the manifest layout of packages built with BuildOptions.Inline
+const LayoutInline = "inline"
+
+// inlineTypes are the media types of files embedded as data URIs
+var inlineTypes = map[string]string{
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".svg":   "image/svg+xml",
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// inliner folds the files referenced by index.html into it
+type inliner struct {
+	files      map[string][]byte
+	inlined    map[string]bool
+	referenced map[string]bool // Files index.html still loads by path after rewriting
+}
+
+// inlineFiles rewrites index.html to embed its stylesheets and scripts, and the
+// images (up to InlineImageLimit) and fonts they reference as data URIs. Embedded
+// files that are no longer referenced are removed: index.html counts only the
+// src, href and url() references left after rewriting, so the names inside the
+// embedded code do not keep a file. It returns the removed package paths.
+func inlineFiles(files map[string][]byte) []string {
+	document, ok := files["index.html"]
+	if !ok {
+		return nil
+	}
+
+	in := &inliner{files: files, inlined: map[string]bool{}, referenced: map[string]bool{}}
+	files["index.html"] = in.document(document)
+
+	var remaining []string
+	for name := range files {
+		if name != "index.html" && !in.inlined[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	var removed []string
+	for name := range in.inlined {
+		if !in.referenced[name] && !referencedBy(files, remaining, name) {
+			delete(files, name)
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// document rewrites an HTML document in the package root
+func (in *inliner) document(document []byte) []byte {
+	var out bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(document))
+	inStyle, skipBody := false, false
+
+	for {
+		kind := z.Next()
+		if kind == html.ErrorToken {
+			return out.Bytes()
+		}
+		raw := z.Raw()
+		var token html.Token
+
+		switch kind {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token = z.Token()
+			switch token.Data {
+			case "link":
+				if css, ok := in.stylesheet(token); ok {
+					out.WriteString(startTag("style", keepAttrs(token.Attr, "media"), false))
+					out.Write(css)
+					out.WriteString("</style>")
+					continue
+				}
+			case "script":
+				if js, ok := in.script(token); ok {
+					out.WriteString(startTag("script", dropAttrs(token.Attr, "src"), false))
+					out.Write(js)
+					if kind == html.SelfClosingTagToken {
+						out.WriteString("</script>")
+					}
+					skipBody = kind == html.StartTagToken
+					continue
+				}
+			case "style":
+				inStyle = kind == html.StartTagToken
+			case "img":
+				if src, ok := attr(token, "src"); ok {
+					if uri, ok := in.dataURI(src, "", false); ok {
+						setAttr(&token, "src", uri)
+						out.WriteString(startTag("img", token.Attr, kind == html.SelfClosingTagToken))
+						continue
+					}
+				}
+			}
+		case html.TextToken:
+			switch {
+			case skipBody:
+				continue
+			case inStyle:
+				out.Write(in.rewriteCSS(raw, ""))
+				continue
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script":
+				skipBody = false
+			case "style":
+				inStyle = false
+			}
+		}
+		if kind == html.StartTagToken || kind == html.SelfClosingTagToken {
+			in.recordTag(token)
+		}
+		out.Write(raw)
+	}
+}
+
+// recordTag notes the local files a tag kept as it is still loads: its src and
+// href attributes and the url() references of its style attribute
+func (in *inliner) recordTag(token html.Token) {
+	for _, a := range token.Attr {
+		switch a.Key {
+		case "src", "href", "poster":
+			in.record(a.Val, "")
+		case "style":
+			for _, match := range cssURLPattern.FindAllStringSubmatch(a.Val, -1) {
+				in.record(match[1], "")
+			}
+		}
+	}
+}
+
+// record notes a reference from a file in dir that is left in the document
+func (in *inliner) record(ref, dir string) {
+	if name, ok := in.resolve(ref, dir); ok {
+		in.referenced[name] = true
+	}
+}
+
+// stylesheet returns the rewritten contents of a local stylesheet link
+func (in *inliner) stylesheet(token html.Token) ([]byte, bool) {
+	rel, _ := attr(token, "rel")
+	href, ok := attr(token, "href")
+	if !ok || !contains(strings.Fields(strings.ToLower(rel)), "stylesheet") {
+		return nil, false
+	}
+	name, ok := in.resolve(href, "")
+	if !ok || bytes.Contains(bytes.ToLower(in.files[name]), []byte("</style")) {
+		return nil, false
+	}
+	in.inlined[name] = true
+	return in.rewriteCSS(in.files[name], path.Dir(name)), true
+}
+
+// script returns the contents of a local external script
+func (in *inliner) script(token html.Token) ([]byte, bool) {
+	src, ok := attr(token, "src")
+	if !ok {
+		return nil, false
+	}
+	name, ok := in.resolve(src, "")
+	if !ok || bytes.Contains(bytes.ToLower(in.files[name]), []byte("</script")) {
+		return nil, false
+	}
+	in.inlined[name] = true
+	return in.files[name], true
+}
+
+// rewriteCSS replaces the url() references of a stylesheet in dir with data URIs
+func (in *inliner) rewriteCSS(css []byte, dir string) []byte {
+	return cssURLPattern.ReplaceAllFunc(css, func(match []byte) []byte {
+		ref := cssURLPattern.FindSubmatch(match)[1]
+		if uri, ok := in.dataURI(string(ref), dir, true); ok {
+			return []byte(`url("` + uri + `")`)
+		}
+		in.record(string(ref), dir)
+		return match
+	})
+}
+
+// dataURI returns a reference from a file in dir as a data URI. Images are
+// embedded up to InlineImageLimit, fonts always where allowed.
+func (in *inliner) dataURI(ref, dir string, allowFonts bool) (string, bool) {
+	name, ok := in.resolve(ref, dir)
+	if !ok {
+		return "", false
+	}
+	ext := strings.ToLower(path.Ext(name))
+	mediaType, ok := inlineTypes[ext]
+	if !ok {
+		return "", false
+	}
+	isFont := strings.HasPrefix(mediaType, "font/")
+	if isFont && !allowFonts || !isFont && len(in.files[name]) > InlineImageLimit {
+		return "", false
+	}
+	in.inlined[name] = true
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(in.files[name]), true
+}
+
+// resolve returns the package path of a local reference from a file in dir
+func (in *inliner) resolve(ref, dir string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	name := path.Join(dir, u.Path)
+	if _, ok := in.files[name]; !ok {
+		return "", false
+	}
+	return name, true
+}
+
+// referencedBy reports whether any of the named text files mentions the file name
+// of target. It is only used for files left outside index.html, whose scripts
+// may build paths at runtime.
+func referencedBy(files map[string][]byte, names []string, target string) bool {
+	base := []byte(path.Base(target))
+	for _, name := range names {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".html", ".htm", ".css", ".js", ".json":
+			if bytes.Contains(files[name], base) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(token html.Token, key string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func setAttr(token *html.Token, key, value string) {
+	for i := range token.Attr {
+		if token.Attr[i].Key == key {
+			token.Attr[i].Val = value
+		}
+	}
+}
+
+// keepAttrs returns only the attributes named in keys
+func keepAttrs(attrs []html.Attribute, keys ...string) []html.Attribute {
+	var kept []html.Attribute
+	for _, a := range attrs {
+		if contains(keys, a.Key) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// dropAttrs returns the attributes not named in keys
+func dropAttrs(attrs []html.Attribute, keys ...string) []html.Attribute {
+	var kept []html.Attribute
+	for _, a := range attrs {
+		if !contains(keys, a.Key) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// startTag renders a start tag with escaped attribute values
+func startTag(name string, attrs []html.Attribute, selfClosing bool) string {
+	var tag strings.Builder
+	tag.WriteString("<" + name)
+	for _, a := range attrs {
+		tag.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	if selfClosing {
+		tag.WriteString("/")
+	}
+	tag.WriteString(">")
+	return tag.String()
+}
@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInlineBuildIsSingleFile(t *testing.T) {
+	result, err := NewBuilder().Build(BuildOptions{
+		Name:            "Inline",
+		Template:        "digital",
+		Permissions:     []string{PermissionSteps},
+		Complications:   []Complication{{Slot: SlotTop, Provider: ProviderDate}, {Slot: SlotBottom, Provider: ProviderBattery}},
+		OutputPath:      t.TempDir(),
+		GeneratePreview: true,
+		Inline:          true,
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := []string{"index.html", "manifest.json", "preview.png"}
+	if !equalStrings(result.Files, want) {
+		t.Errorf("package files = %v, want %v", result.Files, want)
+	}
+}
+
+func TestInlineKeepsReferencedFiles(t *testing.T) {
+	big := bytes.Repeat([]byte{0}, InlineImageLimit+1)
+	files := map[string][]byte{
+		"index.html": []byte(`<html><head><link rel="stylesheet" href="style.css"></head>` +
+			`<body><img src="big.png"><script src="script.js"></script></body></html>`),
+		"style.css": []byte(`body { background: url(big.png); } /* style.css */`),
+		"script.js": []byte(`// script.js, loads loader.js
+var s = document.createElement('script'); s.src = 'loader.js';`),
+		"loader.js": []byte(`console.log('loaded');`),
+		"big.png":   big,
+	}
+
+	removed := inlineFiles(files)
+	if want := []string{"script.js", "style.css"}; !equalStrings(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	for _, name := range []string{"index.html", "big.png", "loader.js"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s was removed", name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+)
+
+// PackageEntry describes a single file in a package
+type PackageEntry struct {
+	Name           string `json:"name"`
+	Size           uint64 `json:"size"`
+	CompressedSize uint64 `json:"compressed_size"`
+	Method         string `json:"method"`
+	SHA256         string `json:"sha256"`
+}
+
+// PreviewInfo describes the preview image embedded in a package
+type PreviewInfo struct {
+	File   string `json:"file"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// PackageInfo is the result of inspecting a package
+type PackageInfo struct {
+	Path     string         `json:"path"`
+	Size     int64          `json:"size"`
+	SHA256   string         `json:"sha256"`
+	Manifest *ManifestData  `json:"manifest,omitempty"`
+	Entries  []PackageEntry `json:"entries"`
+	Preview  *PreviewInfo   `json:"preview,omitempty"`
+}
+
+// InspectPackage reads the manifest, entries, hashes and preview dimensions of a package
+func InspectPackage(zipPath string) (*PackageInfo, error) {
+	fileInfo, err := os.Stat(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	packageHash, err := calculateFileHash(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash package: %w", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func(reader *zip.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	info := &PackageInfo{
+		Path:    zipPath,
+		Size:    fileInfo.Size(),
+		SHA256:  packageHash,
+		Entries: []PackageEntry{},
+	}
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		entryHash, err := hashZipEntry(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", file.Name, err)
+		}
+		info.Entries = append(info.Entries, PackageEntry{
+			Name:           file.Name,
+			Size:           file.UncompressedSize64,
+			CompressedSize: file.CompressedSize64,
+			Method:         compressionMethodName(file.Method),
+			SHA256:         entryHash,
+		})
+
+		switch file.Name {
+		case "manifest.json":
+			content, err := readEntry(file, DefaultMaxPackageSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read manifest.json: %w", err)
+			}
+			var manifest ManifestData
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				return nil, fmt.Errorf("failed to parse manifest.json: %w", err)
+			}
+			info.Manifest = &manifest
+		case "preview.png":
+			content, err := readEntry(file, DefaultMaxPackageSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read preview.png: %w", err)
+			}
+			if config, err := png.DecodeConfig(bytes.NewReader(content)); err == nil {
+				info.Preview = &PreviewInfo{File: file.Name, Width: config.Width, Height: config.Height}
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// hashZipEntry calculates the SHA256 hash of a ZIP entry's uncompressed content
+func hashZipEntry(file *zip.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func(rc io.ReadCloser) {
+		_ = rc.Close()
+	}(rc)
+
+	return hashReader(rc)
+}
+
+// compressionMethodName returns a readable name for a ZIP compression method
+func compressionMethodName(method uint16) string {
+	switch method {
+	case zip.Store:
+		return "store"
+	case zip.Deflate:
+		return "deflate"
+	default:
+		return fmt.Sprintf("method-%d", method)
+	}
+}
@@ -0,0 +1,279 @@
+package builder
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DefaultLocale is the locale of faces built without BuildOptions.Locales
+const DefaultLocale = "zh-CN"
+
+// LocaleFile is the script that formats times and dates for the face's locales
+const LocaleFile = "locale.js"
+
+// Locale holds the names and patterns a face needs to show times and dates in
+// one language and region. Patterns use CLDR field letters: y, M, MM, MMM, MMMM,
+// d, dd, E (short weekday), EEEE, H, HH, h, hh, m, mm, s, ss and a (day period);
+// text in single quotes is literal.
+type Locale struct {
+	Tag           string     `json:"tag"`           // BCP 47 tag, e.g. en-US
+	Weekdays      [7]string  `json:"weekdays"`      // From Sunday
+	ShortWeekdays [7]string  `json:"shortWeekdays"` // From Sunday
+	Months        [12]string `json:"months"`
+	ShortMonths   [12]string `json:"shortMonths"`
+	Date          string     `json:"date"`     // Numeric date, e.g. M/d/y
+	MonthDay      string     `json:"monthDay"` // Numeric month and day, e.g. M/d
+	Time          string     `json:"time"`     // Time with seconds, e.g. h:mm:ss a
+	AM            string     `json:"am"`
+	PM            string     `json:"pm"`
+}
+
+// localesJSON holds the bundled locale tables, derived from CLDR
+//
+//go:embed locales.json
+var localesJSON []byte
+
+// locales are the bundled locales keyed by lower-case tag
+var locales = func() map[string]Locale {
+	var list []Locale
+	if err := json.Unmarshal(localesJSON, &list); err != nil {
+		panic(fmt.Sprintf("invalid locales.json: %v", err))
+	}
+	byTag := make(map[string]Locale, len(list))
+	for _, l := range list {
+		byTag[strings.ToLower(l.Tag)] = l
+	}
+	return byTag
+}()
+
+// Locales returns the tags of all bundled locales, sorted
+func Locales() []string {
+	tags := make([]string, 0, len(locales))
+	for _, l := range locales {
+		tags = append(tags, l.Tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// LookupLocale returns a bundled locale by tag, ignoring case and accepting
+// underscores as in en_US
+func LookupLocale(tag string) (Locale, bool) {
+	l, ok := locales[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))]
+	return l, ok
+}
+
+// normalizeLocales checks locale tags and returns them in canonical form, without duplicates
+func normalizeLocales(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		l, ok := LookupLocale(tag)
+		if !ok {
+			return nil, invalidOptions("unknown locale %q (expected one of %s)", tag, strings.Join(Locales(), ", "))
+		}
+		if !contains(normalized, l.Tag) {
+			normalized = append(normalized, l.Tag)
+		}
+	}
+	return normalized, nil
+}
+
+// ResolvedLocales returns the locales of the face, DefaultLocale if none are set.
+// The first one is the locale of the markup and previews.
+func (o BuildOptions) ResolvedLocales() []string {
+	if len(o.Locales) == 0 {
+		return []string{DefaultLocale}
+	}
+	return o.Locales
+}
+
+// PrimaryLocale returns the first of the face's locales
+func (o BuildOptions) PrimaryLocale() Locale {
+	l, ok := LookupLocale(o.ResolvedLocales()[0])
+	if !ok {
+		l, _ = LookupLocale(DefaultLocale)
+	}
+	return l
+}
+
+// Hour12 reports whether the locale uses a 12-hour clock
+func (l Locale) Hour12() bool {
+	return strings.ContainsRune(l.Time, 'h')
+}
+
+// PeriodFirst reports whether the day period comes before the hours
+func (l Locale) PeriodFirst() bool {
+	period := strings.IndexByte(l.Time, 'a')
+	return period >= 0 && period < strings.IndexAny(l.Time, "hH")
+}
+
+// Hours formats an hour of the day as the locale's time pattern does
+func (l Locale) Hours(hour int) string {
+	start := strings.IndexAny(l.Time, "hH")
+	if start < 0 {
+		return strconv.Itoa(hour)
+	}
+	n := 1
+	for start+n < len(l.Time) && l.Time[start+n] == l.Time[start] {
+		n++
+	}
+	return l.field(l.Time[start], n, time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC))
+}
+
+// DayPeriod returns AM or PM for an hour on a 12-hour clock, otherwise ""
+func (l Locale) DayPeriod(hour int) string {
+	if !strings.ContainsRune(l.Time, 'a') {
+		return ""
+	}
+	if hour < 12 {
+		return l.AM
+	}
+	return l.PM
+}
+
+// FormatDate formats the numeric date of t
+func (l Locale) FormatDate(t time.Time) string {
+	return l.Format(l.Date, t)
+}
+
+// FormatMonthDay formats the month and day of t
+func (l Locale) FormatMonthDay(t time.Time) string {
+	return l.Format(l.MonthDay, t)
+}
+
+// FormatTime formats the time of day of t, with or without seconds
+func (l Locale) FormatTime(t time.Time, seconds bool) string {
+	pattern := l.Time
+	if !seconds {
+		pattern = strings.Replace(strings.Replace(pattern, ":ss", "", 1), ":s", "", 1)
+	}
+	return l.Format(pattern, t)
+}
+
+// Format formats t with a pattern of CLDR field letters
+func (l Locale) Format(pattern string, t time.Time) string {
+	var out strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				end = len(pattern) - i - 1
+			}
+			out.WriteString(pattern[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		out.WriteString(l.field(c, n, t))
+		i += n
+	}
+	return out.String()
+}
+
+// field formats one pattern field of n repeated letters
+func (l Locale) field(letter byte, n int, t time.Time) string {
+	number := func(v int) string {
+		s := strconv.Itoa(v)
+		for len(s) < n {
+			s = "0" + s
+		}
+		return s
+	}
+	switch letter {
+	case 'y':
+		return number(t.Year())
+	case 'M':
+		switch {
+		case n >= 4:
+			return l.Months[t.Month()-1]
+		case n == 3:
+			return l.ShortMonths[t.Month()-1]
+		}
+		return number(int(t.Month()))
+	case 'd':
+		return number(t.Day())
+	case 'E':
+		if n >= 4 {
+			return l.Weekdays[t.Weekday()]
+		}
+		return l.ShortWeekdays[t.Weekday()]
+	case 'H':
+		return number(t.Hour())
+	case 'h':
+		return number((t.Hour()+11)%12 + 1)
+	case 'm':
+		return number(t.Minute())
+	case 's':
+		return number(t.Second())
+	case 'a':
+		if t.Hour() < 12 {
+			return l.AM
+		}
+		return l.PM
+	}
+	return strings.Repeat(string(letter), n)
+}
+
+//go:embed locale.js
+var localeJS string
+
+var localeJSTemplate = texttemplate.Must(texttemplate.New("locale.js").Parse(localeJS))
+
+// localeScript renders locale.js with the tables of the face's locales
+func localeScript(options BuildOptions) ([]byte, error) {
+	var tables []Locale
+	for _, tag := range options.ResolvedLocales() {
+		if l, ok := LookupLocale(tag); ok {
+			tables = append(tables, l)
+		}
+	}
+	tablesJSON, err := json.Marshal(tables)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := localeJSTemplate.Execute(&buf, struct{ Locales string }{string(tablesJSON)}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addLocales adds locale.js, with the tables of the face's locales, to packages
+// whose index.html loads it, that have complications, or whose scripts are the
+// user's own (custom and source faces) and that are built for explicit locales,
+// and loads it from index.html ahead of the face's own scripts. Like the bridge,
+// the generated script replaces any copy in the sources.
+func addLocales(files map[string][]byte, options BuildOptions) error {
+	index, hasIndex := files["index.html"]
+	referenced := hasIndex && bytes.Contains(index, []byte(LocaleFile))
+	userScripts := options.Template == "custom" || options.SourceDir != ""
+	if !referenced && len(options.Complications) == 0 && !(userScripts && len(options.Locales) > 0) {
+		return nil
+	}
+	script, err := localeScript(options)
+	if err != nil {
+		return err
+	}
+	files[LocaleFile] = script
+
+	if hasIndex && !referenced {
+		files["index.html"] = InjectScripts(index, LocaleFile)
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package builder
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The minifiers are deliberately conservative: they drop comments and
+// whitespace but never rename, reorder or rewrite code, so that the output
+// behaves exactly like the input on every WebView.
+
+// MinifyCSS removes comments and the whitespace CSS does not need
+func MinifyCSS(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+	pendingSpace := false
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3
+			}
+			pendingSpace = true
+		case c == '"' || c == '\'':
+			end := skipQuoted(src, i)
+			writeCSSSpace(&out, pendingSpace, c)
+			out.Write(src[i:end])
+			i = end - 1
+			pendingSpace = false
+		case isSpace(c):
+			pendingSpace = true
+		default:
+			if c == '}' {
+				trimTrailingByte(&out, ';')
+			}
+			writeCSSSpace(&out, pendingSpace, c)
+			out.WriteByte(c)
+			pendingSpace = false
+		}
+	}
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// writeCSSSpace writes a pending space unless the punctuation around it makes it redundant
+func writeCSSSpace(out *bytes.Buffer, pending bool, next byte) {
+	if !pending || out.Len() == 0 {
+		return
+	}
+	prev := out.Bytes()[out.Len()-1]
+	if strings.IndexByte("{};,:(>", prev) >= 0 || strings.IndexByte("{};,)>", next) >= 0 {
+		return
+	}
+	out.WriteByte(' ')
+}
+
+// jsRegexPrefixes are the keywords after which a slash starts a regular expression
+var jsRegexPrefixes = []string{"return", "typeof", "instanceof", "case", "do", "else", "in", "of", "new", "delete", "void", "throw", "yield", "await"}
+
+// MinifyJS removes comments, indentation and blank lines. Line breaks are kept
+// where automatic semicolon insertion could depend on them.
+func MinifyJS(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+	pendingSpace, pendingNewline := false, false
+
+	flush := func(next byte) {
+		if out.Len() > 0 {
+			prev := out.Bytes()[out.Len()-1]
+			switch {
+			case pendingNewline && strings.IndexByte("{;,([", prev) < 0:
+				out.WriteByte('\n')
+			case pendingSpace || pendingNewline:
+				if isIdentByte(prev) && (isIdentByte(next) || next == '.') || strings.IndexByte("+-", prev) >= 0 && prev == next {
+					out.WriteByte(' ')
+				}
+			}
+		}
+		pendingSpace, pendingNewline = false, false
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			pendingNewline = true
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			if bytes.IndexByte(src[i+2:i+2+end], '\n') >= 0 {
+				pendingNewline = true
+			} else {
+				pendingSpace = true
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			flush(c)
+			end := skipQuoted(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		case c == '`':
+			flush(c)
+			end := skipTemplate(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		case c == '/' && startsRegex(out.Bytes()):
+			flush(c)
+			end := skipRegex(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		case c == '\n':
+			pendingNewline = true
+		case isSpace(c):
+			pendingSpace = true
+		default:
+			flush(c)
+			out.WriteByte(c)
+		}
+	}
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// startsRegex reports whether a slash following the code so far starts a
+// regular expression rather than dividing the operand before it
+func startsRegex(code []byte) bool {
+	code = bytes.TrimRight(code, " \t\r\n")
+	if len(code) == 0 {
+		return true
+	}
+	last := code[len(code)-1]
+	// A postfix increment or decrement ends an operand, as in a++ / 2
+	if (last == '+' || last == '-') && bytes.HasSuffix(code, []byte{last, last}) {
+		operand := bytes.TrimRight(code[:len(code)-2], " \t\r\n")
+		return len(operand) == 0 || !endsOperand(operand[len(operand)-1])
+	}
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", last) >= 0 {
+		return true
+	}
+	for _, keyword := range jsRegexPrefixes {
+		if bytes.HasSuffix(code, []byte(keyword)) {
+			rest := code[:len(code)-len(keyword)]
+			if len(rest) == 0 || !isIdentByte(rest[len(rest)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// endsOperand reports whether c can end an operand: an identifier, a number,
+// or a closing parenthesis or bracket
+func endsOperand(c byte) bool {
+	return isIdentByte(c) || c == ')' || c == ']'
+}
+
+// skipRegex returns the index just past the regular expression literal at src[start]
+func skipRegex(src []byte, start int) int {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if !inClass {
+				i++
+				for i < len(src) && isIdentByte(src[i]) {
+					i++
+				}
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// skipTemplate returns the index just past the template literal at src[start],
+// stepping over the strings, templates and braces of its substitutions
+func skipTemplate(src []byte, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			return i + 1
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				i = skipSubstitution(src, i+2) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// skipSubstitution returns the index just past the brace closing the template
+// substitution whose expression starts at src[start]
+func skipSubstitution(src []byte, start int) int {
+	depth := 0
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			i = skipQuoted(src, i) - 1
+		case '`':
+			i = skipTemplate(src, i) - 1
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i + 1
+			}
+			depth--
+		}
+	}
+	return len(src)
+}
+
+// skipQuoted returns the index just past the string literal at src[start]
+func skipQuoted(src []byte, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+func trimTrailingByte(out *bytes.Buffer, c byte) {
+	if out.Len() > 0 && out.Bytes()[out.Len()-1] == c {
+		out.Truncate(out.Len() - 1)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// blockElements are the elements around which whitespace is never rendered
+var blockElements = map[string]bool{
+	"html": true, "head": true, "body": true, "title": true, "meta": true, "link": true,
+	"script": true, "style": true, "base": true, "noscript": true, "template": true,
+	"div": true, "p": true, "section": true, "article": true, "header": true, "footer": true,
+	"main": true, "nav": true, "aside": true, "ul": true, "ol": true, "li": true,
+	"table": true, "thead": true, "tbody": true, "tr": true, "td": true, "th": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "canvas": true, "svg": true, "br": true, "hr": true, "form": true,
+}
+
+// MinifyHTML removes comments and collapses whitespace, and minifies inline
+// styles and scripts. Whitespace is kept inside pre and textarea.
+func MinifyHTML(src []byte) []byte {
+	type token struct {
+		kind html.TokenType
+		name string
+		raw  []byte
+	}
+
+	var tokens []token
+	z := html.NewTokenizer(bytes.NewReader(src))
+	for {
+		kind := z.Next()
+		if kind == html.ErrorToken {
+			break
+		}
+		t := token{kind: kind, raw: append([]byte(nil), z.Raw()...)}
+		if kind == html.StartTagToken || kind == html.EndTagToken || kind == html.SelfClosingTagToken {
+			name, _ := z.TagName()
+			t.name = string(name)
+		}
+		tokens = append(tokens, t)
+	}
+
+	isBlock := func(i int) bool {
+		if i < 0 || i >= len(tokens) {
+			return true
+		}
+		switch tokens[i].kind {
+		case html.DoctypeToken:
+			return true
+		case html.TextToken, html.CommentToken:
+			return false
+		}
+		return blockElements[tokens[i].name]
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(src))
+	var rawText string // Enclosing script or style element
+	var rawType string // Type attribute of the enclosing script
+	preserve := 0      // Depth of pre and textarea elements
+
+	for i, t := range tokens {
+		switch t.kind {
+		case html.CommentToken:
+			if bytes.HasPrefix(t.raw, []byte("<!--[if")) {
+				out.Write(t.raw)
+			}
+		case html.TextToken:
+			switch {
+			case rawText == "style":
+				out.Write(MinifyCSS(t.raw))
+			case rawText == "script" && isJavaScriptType(rawType):
+				out.Write(MinifyJS(t.raw))
+			case rawText != "" || preserve > 0:
+				out.Write(t.raw)
+			default:
+				text := collapseSpace(t.raw)
+				if isBlock(i - 1) {
+					text = bytes.TrimLeft(text, " ")
+				}
+				if isBlock(i + 1) {
+					text = bytes.TrimRight(text, " ")
+				}
+				out.Write(text)
+			}
+		default:
+			out.Write(t.raw)
+		}
+
+		switch t.kind {
+		case html.StartTagToken:
+			switch t.name {
+			case "script", "style":
+				rawText, rawType = t.name, attributeValue(t.raw, "type")
+			case "pre", "textarea":
+				preserve++
+			}
+		case html.EndTagToken:
+			switch t.name {
+			case "script", "style":
+				rawText = ""
+			case "pre", "textarea":
+				if preserve > 0 {
+					preserve--
+				}
+			}
+		}
+	}
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// isJavaScriptType reports whether a script type attribute denotes JavaScript
+func isJavaScriptType(scriptType string) bool {
+	switch strings.ToLower(strings.TrimSpace(scriptType)) {
+	case "", "text/javascript", "application/javascript", "module":
+		return true
+	}
+	return false
+}
+
+// attributeValue returns an attribute of a raw start tag
+func attributeValue(rawTag []byte, name string) string {
+	z := html.NewTokenizer(bytes.NewReader(rawTag))
+	z.Next()
+	if _, hasAttr := z.TagName(); !hasAttr {
+		return ""
+	}
+	for {
+		key, value, more := z.TagAttr()
+		if string(key) == name {
+			return string(value)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// collapseSpace replaces every run of whitespace with a single space
+func collapseSpace(text []byte) []byte {
+	var out []byte
+	space := false
+	for _, c := range text {
+		if isSpace(c) {
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, c)
+	}
+	if space {
+		out = append(out, ' ')
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package builder
+
+import "testing"
+
+func TestMinifyCSS(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"whitespace", "body {\n  margin: 0;\n  padding: 0 ;\n}\n", "body{margin:0;padding:0}"},
+		{"comments", "/* reset */\na { color: red; /* inline */ }", "a{color:red}"},
+		{"comment in string", `a::before { content: "/* kept */"; }`, `a::before{content:"/* kept */"}`},
+		{"escaped quote", `a::after { content: 'it\'s  here'; }`, `a::after{content:'it\'s  here'}`},
+		{"descendant selectors", ".a  .b > .c ,\n.d { x: 1 }", ".a .b>.c,.d{x:1}"},
+		{"pseudo-class on descendant", "a :hover { x: 1 }", "a :hover{x:1}"},
+		{"values", "a { margin: 0 auto; font: 12px / 1.5 sans-serif; }", "a{margin:0 auto;font:12px / 1.5 sans-serif}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyCSS([]byte(tt.src))); got != tt.want {
+				t.Errorf("MinifyCSS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifyJS(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"indentation", "function f(a, b) {\n    return a + b;\n}\n", "function f(a,b){return a+b;}"},
+		{"comments", "// header\nvar a = 1; /* note */ var b = 2;", "var a=1;var b=2;"},
+		{"comment in string", `var s = "// not a comment", t = '/* nor this */';`, `var s="// not a comment",t='/* nor this */';`},
+		{"url in string", `var u = "http://example.com"; // real comment`, `var u="http://example.com";`},
+		{"division", "var x = a / b / c;", "var x=a/b/c;"},
+		{"division after postfix", "var c = a++ / 2; var d = b / 3;", "var c=a++/2;var d=b/3;"},
+		{"division after decrement", "var c = a-- / 2, d = b / 3;", "var c=a--/2,d=b/3;"},
+		{"division after call", "var r = f(x) / 2; var s = y / 4;", "var r=f(x)/2;var s=y/4;"},
+		{"division after index", "var r = a[0] / 2; var s = y / 4;", "var r=a[0]/2;var s=y/4;"},
+		{"regex after assignment", "var re = / +\\/ x/g;", "var re=/ +\\/ x/g;"},
+		{"regex after return", "function f() { return /a  b/.test(s); }", "function f(){return/a  b/.test(s);}"},
+		{"regex after operator", "ok = ok && /  x/.test(s);", "ok=ok&&/  x/.test(s);"},
+		{"regex after prefix increment", "x = ++ /a/.lastIndex;", "x=++/a/.lastIndex;"},
+		{"regex class with slash", "var re = /[/  ]+/;", "var re=/[/  ]+/;"},
+		{"template literal", "var t = `a  // b\n  /* c */`;", "var t=`a  // b\n  /* c */`;"},
+		{"nested template", "var t = `x ${ y ? `a  b` : '}' } z`; var u = 1;", "var t=`x ${ y ? `a  b` : '}' } z`;var u=1;"},
+		{"asi line breaks", "var a = b\n(c || d).e()\nx\n++y\nreturn\n", "var a=b\n(c||d).e()\nx\n++y\nreturn"},
+		{"increments", "a = b + +c; d = e - -f; g = h+ ++i;", "a=b+ +c;d=e- -f;g=h+ ++i;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyJS([]byte(tt.src))); got != tt.want {
+				t.Errorf("MinifyJS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifyHTML(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"whitespace", "<div>\n  <p>Hello   <b>big</b>  world</p>\n</div>", "<div><p>Hello <b>big</b> world</p></div>"},
+		{"comments", "<body><!-- hidden --><p>a</p><!--[if IE]><p>ie</p><![endif]--></body>", "<body><p>a</p><!--[if IE]><p>ie</p><![endif]--></body>"},
+		{"pre", "<pre>\n  a   b\n</pre>", "<pre>\n  a   b\n</pre>"},
+		{"textarea", "<textarea>  x\n   y </textarea>", "<textarea>  x\n   y </textarea>"},
+		{"inline style", "<style>\n  a { color: red; }\n</style>", "<style>a{color:red}</style>"},
+		{"inline script", "<script>\n  var a = 1; // one\n</script>", "<script>var a=1;</script>"},
+		{"module script", "<script type=\"module\">\n  var a = 1;\n</script>", "<script type=\"module\">var a=1;</script>"},
+		{"template script", "<script type=\"text/template\">\n  <p>  {{ x }}  </p> // kept\n</script>",
+			"<script type=\"text/template\">\n  <p>  {{ x }}  </p> // kept\n</script>"},
+		{"json script", "<script type=\"application/ld+json\">\n  { \"a\": \"b  c\" }\n</script>",
+			"<script type=\"application/ld+json\">\n  { \"a\": \"b  c\" }\n</script>"},
+		{"comment in script", "<script>var s = '<!-- x -->  ';</script>", "<script>var s='<!-- x -->  ';</script>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyHTML([]byte(tt.src))); got != tt.want {
+				t.Errorf("MinifyHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
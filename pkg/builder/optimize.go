@@ -0,0 +1,160 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AssetSavings reports the size of a file before and after the asset pipeline
+type AssetSavings struct {
+	File   string `json:"file"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// Saved returns the number of bytes saved
+func (s AssetSavings) Saved() int {
+	return s.Before - s.After
+}
+
+// assetStage optimizes one kind of file
+type assetStage struct {
+	enabled    func(options BuildOptions) bool
+	extensions []string
+	optimize   func(content []byte) ([]byte, error)
+}
+
+// assetStages make up the asset pipeline, one stage per option
+var assetStages = []assetStage{
+	{
+		enabled:    func(o BuildOptions) bool { return o.MinifyHTML },
+		extensions: []string{".html", ".htm"},
+		optimize:   func(content []byte) ([]byte, error) { return MinifyHTML(content), nil },
+	},
+	{
+		enabled:    func(o BuildOptions) bool { return o.MinifyCSS },
+		extensions: []string{".css"},
+		optimize:   func(content []byte) ([]byte, error) { return MinifyCSS(content), nil },
+	},
+	{
+		enabled:    func(o BuildOptions) bool { return o.MinifyJS },
+		extensions: []string{".js"},
+		optimize:   func(content []byte) ([]byte, error) { return MinifyJS(content), nil },
+	},
+	{
+		enabled:    func(o BuildOptions) bool { return o.OptimizeImages },
+		extensions: []string{".png"},
+		optimize:   RecompressPNG,
+	},
+}
+
+// optimizeAssets runs the enabled pipeline stages over the files written to dir,
+// keeping an optimized file only if it is smaller. Files already named .min.css
+// or .min.js are left alone.
+func optimizeAssets(dir string, files []string, options BuildOptions) ([]AssetSavings, error) {
+	var savings []AssetSavings
+	for _, name := range files {
+		stage := stageFor(name, options)
+		if stage == nil {
+			continue
+		}
+
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		optimized, err := stage.optimize(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to optimize %s: %w", name, err)
+		}
+		if len(optimized) >= len(content) {
+			continue
+		}
+		if err := os.WriteFile(filePath, optimized, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write file %s: %w", name, err)
+		}
+		savings = append(savings, AssetSavings{File: name, Before: len(content), After: len(optimized)})
+	}
+
+	sort.Slice(savings, func(i, j int) bool { return savings[i].File < savings[j].File })
+	return savings, nil
+}
+
+// stageFor returns the enabled stage handling a file, if any
+func stageFor(name string, options BuildOptions) *assetStage {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".min.css") || strings.HasSuffix(lower, ".min.js") {
+		return nil
+	}
+	ext := path.Ext(lower)
+	for i := range assetStages {
+		if assetStages[i].enabled(options) && contains(assetStages[i].extensions, ext) {
+			return &assetStages[i]
+		}
+	}
+	return nil
+}
+
+// RecompressPNG re-encodes a PNG at the best compression level, as a palette
+// image when it has at most 256 colours. Pixels are unchanged; ancillary chunks
+// such as text and colour profiles are dropped.
+func RecompressPNG(content []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if paletted, ok := toPaletted(img); ok {
+		img = paletted
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toPaletted converts an 8-bit image with at most 256 distinct colours to a
+// palette image without changing any pixel
+func toPaletted(img image.Image) (*image.Paletted, bool) {
+	switch img.(type) {
+	case *image.Paletted, *image.Gray, *image.Gray16, *image.RGBA64, *image.NRGBA64:
+		return nil, false
+	}
+
+	bounds := img.Bounds()
+	index := map[color.NRGBA]uint8{}
+	var palette color.Palette
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if _, ok := index[c]; ok {
+				continue
+			}
+			if len(palette) == 256 {
+				return nil, false
+			}
+			index[c] = uint8(len(palette))
+			palette = append(palette, c)
+		}
+	}
+
+	paletted := image.NewPaletted(bounds, palette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			paletted.SetColorIndex(x, y, index[c])
+		}
+	}
+	return paletted, true
+}
@@ -0,0 +1,272 @@
+package builder
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+const (
+	// defaultPreviewSize is the preview size when no device is targeted
+	defaultPreviewSize = 512
+	// previewsDir holds previews for target devices other than the primary one
+	previewsDir = "previews"
+	// storeDir holds store listing images
+	storeDir = "store"
+)
+
+// storeImages are the store listing images generated with StoreImages
+var storeImages = []struct {
+	name          string
+	width, height int
+}{
+	{"store/thumbnail.png", 256, 256},
+	{"store/banner.png", 1024, 500},
+}
+
+// DefaultPreviewTime is the time shown in previews when BuildOptions.PreviewTime is zero.
+// 10:08 frames the logo area on analog faces, like watch advertising photos.
+var DefaultPreviewTime = time.Date(2025, 1, 21, 10, 8, 36, 0, time.UTC)
+
+// PreviewRenderer is implemented by templates that can draw a faithful preview
+// of the face. The context covers the whole screen in physical pixels; scale is
+// the number of physical pixels per CSS pixel. font is options.Font parsed, or
+// nil if the face bundles no font.
+type PreviewRenderer interface {
+	RenderPreview(dc *gg.Context, options BuildOptions, font *truetype.Font, scale float64, at time.Time) error
+}
+
+// errNoPreviewRenderer is returned by templates that fall back to a placeholder preview
+var errNoPreviewRenderer = errors.New("template has no preview renderer")
+
+// previewSurface is the area a preview is rendered for
+type previewSurface struct {
+	width, height int
+	round         bool
+	scale         float64 // Physical pixels per CSS pixel
+}
+
+// surfaceFor returns the preview surface of a device at its physical resolution
+func surfaceFor(device DeviceProfile) previewSurface {
+	return previewSurface{width: device.Width, height: device.Height, round: device.IsRound(), scale: device.DPR}
+}
+
+// ParsePreviewTime parses a preview time given as HH:MM, HH:MM:SS or RFC 3339.
+// Times of day are placed on the date of DefaultPreviewTime.
+func ParsePreviewTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			d := DefaultPreviewTime
+			return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, invalidOptions("invalid preview time %q (expected HH:MM, HH:MM:SS or RFC 3339)", value)
+}
+
+// previewTime returns the time shown in previews
+func previewTime(options BuildOptions) time.Time {
+	if options.PreviewTime.IsZero() {
+		return DefaultPreviewTime
+	}
+	return options.PreviewTime
+}
+
+// generatePreviews writes preview.png at the primary device's size and shape,
+// a preview per additional device and, if requested, the store listing images.
+// It returns the package paths written.
+func (b *Builder) generatePreviews(dir string, options BuildOptions) ([]string, error) {
+	// Parse the bundled font once for every image rather than once per draw
+	var font *truetype.Font
+	if len(options.Font) > 0 {
+		parsed, err := parseFont(options.Font)
+		if err != nil {
+			return nil, err
+		}
+		font = parsed
+	}
+
+	primary := previewSurface{width: defaultPreviewSize, height: defaultPreviewSize, scale: 1}
+	if device, ok := options.PrimaryDevice(); ok {
+		primary = surfaceFor(device)
+	}
+
+	images := map[string]image.Image{
+		"preview.png": b.renderFacePreview(options, font, primary),
+	}
+	order := []string{"preview.png"}
+
+	for _, id := range options.Devices[min(1, len(options.Devices)):] {
+		device, _ := LookupDevice(id)
+		name := previewsDir + "/" + device.ID + ".png"
+		images[name] = b.renderFacePreview(options, font, surfaceFor(device))
+		order = append(order, name)
+	}
+
+	if options.StoreImages {
+		for _, store := range storeImages {
+			images[store.name] = b.renderStoreImage(options, font, primary, store.width, store.height)
+			order = append(order, store.name)
+		}
+	}
+
+	for _, name := range order {
+		if err := writePNG(filepath.Join(dir, filepath.FromSlash(name)), images[name]); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// renderFacePreview renders the face on a surface, clipped to a circle for round
+// screens. Templates that cannot draw themselves get a placeholder with the face name.
+func (b *Builder) renderFacePreview(options BuildOptions, font *truetype.Font, surface previewSurface) image.Image {
+	if options.SourceDir == "" {
+		if tmpl, ok := b.registry.Lookup(options.Template); ok {
+			if renderer, ok := tmpl.(PreviewRenderer); ok {
+				dc := newSurfaceContext(surface)
+				if err := renderer.RenderPreview(dc, options, font, surface.scale, previewTime(options)); err == nil {
+					return dc.Image()
+				}
+			}
+		}
+	}
+
+	dc := newSurfaceContext(surface)
+	drawPlaceholderFace(dc, options, surface)
+	return dc.Image()
+}
+
+// newSurfaceContext returns a drawing context for a surface, clipped to its shape
+func newSurfaceContext(surface previewSurface) *gg.Context {
+	dc := gg.NewContext(surface.width, surface.height)
+	if surface.round {
+		w, h := float64(surface.width), float64(surface.height)
+		dc.DrawEllipse(w/2, h/2, w/2, h/2)
+		dc.Clip()
+	}
+	return dc
+}
+
+// drawPlaceholderFace draws the template background with the face name
+func drawPlaceholderFace(dc *gg.Context, options BuildOptions, surface previewSurface) {
+	bgColor1, bgColor2 := previewBackground(options)
+	drawLinearGradient(dc, 135, bgColor1, bgColor2)
+
+	// Draw text
+	dc.SetRGB(1, 1, 1)
+	dc.DrawStringAnchored(options.Name, float64(surface.width)/2, float64(surface.height)/2, 0.5, 0.5)
+}
+
+// renderStoreImage renders a store listing image with the face preview, framed
+// by a bezel, centred on a darkened version of the template's background
+func (b *Builder) renderStoreImage(options BuildOptions, font *truetype.Font, surface previewSurface, width, height int) image.Image {
+	dc := gg.NewContext(width, height)
+	bgColor1, bgColor2 := previewBackground(options)
+	drawVerticalGradient(dc, darken(bgColor1, 0.5), darken(bgColor2, 0.5))
+
+	// Scale the face to fit 80% of the image while keeping its aspect ratio
+	scale := 0.8 * float64(height) / float64(surface.height)
+	if widthScale := 0.8 * float64(width) / float64(surface.width); widthScale < scale {
+		scale = widthScale
+	}
+	faceWidth := int(float64(surface.width) * scale)
+	faceHeight := int(float64(surface.height) * scale)
+	face := b.renderFacePreview(options, font, previewSurface{
+		width:  faceWidth,
+		height: faceHeight,
+		round:  surface.round,
+		scale:  surface.scale * scale,
+	})
+
+	// Bezel
+	const bezel = 8
+	cx, cy := float64(width)/2, float64(height)/2
+	dc.SetRGB255(24, 24, 28)
+	if surface.round {
+		dc.DrawEllipse(cx, cy, float64(faceWidth)/2+bezel, float64(faceHeight)/2+bezel)
+	} else {
+		dc.DrawRoundedRectangle(cx-float64(faceWidth)/2-bezel, cy-float64(faceHeight)/2-bezel,
+			float64(faceWidth)+2*bezel, float64(faceHeight)+2*bezel, 3*bezel)
+	}
+	dc.Fill()
+
+	dc.DrawImageAnchored(face, width/2, height/2, 0.5, 0.5)
+	return dc.Image()
+}
+
+// darken scales the RGB channels of a colour by factor
+func darken(c color.Color, factor float64) color.Color {
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{
+		R: uint16(float64(r) * factor),
+		G: uint16(float64(g) * factor),
+		B: uint16(float64(b) * factor),
+		A: uint16(a),
+	}
+}
+
+// previewBackground returns the background gradient colours of the face's theme
+func previewBackground(options BuildOptions) (color.Color, color.Color) {
+	theme := options.ResolvedTheme()
+	return themeColor(theme.Background[0], color.Black), themeColor(theme.Background[1], color.Black)
+}
+
+// withOpacity scales the alpha of a colour, like CSS opacity
+func withOpacity(c color.Color, opacity float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(float64(n.A)*opacity + 0.5)
+	return n
+}
+
+// drawVerticalGradient fills the context with a top-to-bottom gradient
+func drawVerticalGradient(dc *gg.Context, top, bottom color.Color) {
+	gradient := gg.NewLinearGradient(0, 0, 0, float64(dc.Height()))
+	gradient.AddColorStop(0, top)
+	gradient.AddColorStop(1, bottom)
+	dc.SetFillStyle(gradient)
+	dc.DrawRectangle(0, 0, float64(dc.Width()), float64(dc.Height()))
+	dc.Fill()
+}
+
+// drawLinearGradient fills the context like a CSS linear-gradient at angle
+// degrees, where 0 points up and 90 points right
+func drawLinearGradient(dc *gg.Context, angle float64, from, to color.Color) {
+	w, h := float64(dc.Width()), float64(dc.Height())
+	dx, dy := math.Sin(gg.Radians(angle)), -math.Cos(gg.Radians(angle))
+	half := (math.Abs(w*dx) + math.Abs(h*dy)) / 2
+
+	gradient := gg.NewLinearGradient(w/2-dx*half, h/2-dy*half, w/2+dx*half, h/2+dy*half)
+	gradient.AddColorStop(0, from)
+	gradient.AddColorStop(1, to)
+	dc.SetFillStyle(gradient)
+	dc.DrawRectangle(0, 0, w, h)
+	dc.Fill()
+}
+
+// writePNG encodes an image to a PNG file, creating parent directories
+func writePNG(outputPath string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	return png.Encode(file, img)
+}
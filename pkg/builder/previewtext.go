@@ -0,0 +1,205 @@
+package builder
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+)
+
+// Bundled fonts standing in for the faces' CSS font stacks, so previews look the
+// same on every machine: Go Regular for system sans-serif, Go Mono for monospace.
+var previewFonts struct {
+	once                          sync.Once
+	regular, bold, mono, monoBold *truetype.Font
+	err                           error
+}
+
+// loadPreviewFonts parses the bundled fonts once
+func loadPreviewFonts() error {
+	previewFonts.once.Do(func() {
+		fonts := []struct {
+			ttf    []byte
+			target **truetype.Font
+		}{
+			{goregular.TTF, &previewFonts.regular},
+			{gobold.TTF, &previewFonts.bold},
+			{gomono.TTF, &previewFonts.mono},
+			{gomonobold.TTF, &previewFonts.monoBold},
+		}
+		for _, f := range fonts {
+			parsed, err := truetype.Parse(f.ttf)
+			if err != nil {
+				previewFonts.err = err
+				return
+			}
+			*f.target = parsed
+		}
+	})
+	return previewFonts.err
+}
+
+// previewFont returns the font bundled with the face, if any, or the Go font
+// standing in for the theme's CSS font family list: Go Mono for monospace
+// families, Go Regular otherwise
+func previewFont(options BuildOptions, bundled *truetype.Font, bold bool) *truetype.Font {
+	if bundled != nil {
+		return bundled
+	}
+	lower := strings.ToLower(options.ResolvedTheme().FontFamily)
+	mono := strings.Contains(lower, "mono") || strings.Contains(lower, "courier")
+	switch {
+	case mono && bold:
+		return previewFonts.monoBold
+	case mono:
+		return previewFonts.mono
+	case bold:
+		return previewFonts.bold
+	default:
+		return previewFonts.regular
+	}
+}
+
+// hasGlyphs reports whether the font can draw every rune of s
+func hasGlyphs(f *truetype.Font, s string) bool {
+	for _, r := range s {
+		if r != ' ' && f.Index(r) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// textShadow is a CSS text-shadow, in pixels
+type textShadow struct {
+	dx, dy, blur float64
+	color        color.Color
+}
+
+// previewLine is a centred line of text laid out like a CSS block. Sizes are in
+// pixels of the context being drawn on.
+type previewLine struct {
+	text          string
+	font          *truetype.Font
+	size          float64
+	letterSpacing float64 // Added after every character, like CSS letter-spacing
+	marginTop     float64
+	color         color.Color
+	shadows       []textShadow
+}
+
+// drawPreviewLines draws lines stacked and centred on the context, the way the
+// templates centre their container with flexbox
+func drawPreviewLines(dc *gg.Context, lines []previewLine) {
+	faces := make([]font.Face, len(lines))
+	total := 0.0
+	for i, line := range lines {
+		faces[i] = truetype.NewFace(line.font, &truetype.Options{Size: line.size, Hinting: font.HintingNone})
+		total += line.marginTop + lineHeight(faces[i])
+	}
+
+	cx := float64(dc.Width()) / 2
+	top := (float64(dc.Height()) - total) / 2
+	for i, line := range lines {
+		top += line.marginTop
+		metrics := faces[i].Metrics()
+		ascent := fixedToFloat(metrics.Ascent)
+		descent := fixedToFloat(metrics.Descent)
+		baseline := top + (lineHeight(faces[i])-ascent-descent)/2 + ascent
+
+		for _, shadow := range line.shadows {
+			layer := gg.NewContext(dc.Width(), dc.Height())
+			layer.SetFontFace(faces[i])
+			layer.SetColor(shadow.color)
+			drawSpacedText(layer, line.text, line.letterSpacing, cx+shadow.dx, baseline+shadow.dy)
+			img := layer.Image().(*image.RGBA)
+			// CSS blur radii are twice the standard deviation
+			boxBlur(img, int(math.Round(shadow.blur/2)))
+			dc.DrawImage(img, 0, 0)
+		}
+
+		dc.SetFontFace(faces[i])
+		dc.SetColor(line.color)
+		drawSpacedText(dc, line.text, line.letterSpacing, cx, baseline)
+		top += lineHeight(faces[i])
+	}
+}
+
+// lineHeight returns the CSS "normal" line height of a face
+func lineHeight(face font.Face) float64 {
+	return fixedToFloat(face.Metrics().Height)
+}
+
+// drawSpacedText draws text centred on cx with extra spacing after every character
+func drawSpacedText(dc *gg.Context, text string, spacing, cx, baseline float64) {
+	runes := []rune(text)
+	width := 0.0
+	for _, r := range runes {
+		w, _ := dc.MeasureString(string(r))
+		width += w + spacing
+	}
+
+	x := cx - width/2
+	for _, r := range runes {
+		dc.DrawString(string(r), x, baseline)
+		w, _ := dc.MeasureString(string(r))
+		x += w + spacing
+	}
+}
+
+// boxBlur blurs an image in place with three box blur passes in each direction,
+// which approximates a Gaussian blur with standard deviation radius
+func boxBlur(img *image.RGBA, radius int) {
+	if radius < 1 {
+		return
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	buffer := make([]uint8, len(img.Pix))
+
+	for pass := 0; pass < 3; pass++ {
+		blurLines(img.Pix, buffer, height, width, img.Stride, 4, radius)
+		blurLines(buffer, img.Pix, width, height, 4, img.Stride, radius)
+	}
+}
+
+// blurLines box-blurs count lines of length pixels from src into dst. Lines start
+// lineStep bytes apart and their pixels are pixelStep bytes apart.
+func blurLines(src, dst []uint8, count, length, lineStep, pixelStep, radius int) {
+	window := 2*radius + 1
+	for line := 0; line < count; line++ {
+		start := line * lineStep
+		for c := 0; c < 4; c++ {
+			at := func(i int) int {
+				if i < 0 || i >= length {
+					return 0
+				}
+				return int(src[start+i*pixelStep+c])
+			}
+
+			sum := 0
+			for i := -radius; i <= radius; i++ {
+				sum += at(i)
+			}
+			for i := 0; i < length; i++ {
+				dst[start+i*pixelStep+c] = uint8(sum / window)
+				sum += at(i+radius+1) - at(i-radius)
+			}
+		}
+	}
+}
+
+// fixedToFloat converts a 26.6 fixed point value to pixels
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
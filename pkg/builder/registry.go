@@ -0,0 +1,142 @@
+package builder
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Template generates the files of a watchface package
+type Template interface {
+	ID() string          // Unique template identifier, e.g. "analog"
+	Name() string        // Display name
+	Description() string // Short description
+	Category() string    // Category such as "digital" or "analog"
+	Generate(options BuildOptions) (map[string][]byte, error)
+}
+
+// OptionsValidator is implemented by templates that require extra build options
+type OptionsValidator interface {
+	ValidateOptions(options BuildOptions) error
+}
+
+// TemplateDetails is implemented by templates that advertise catalogue details
+type TemplateDetails interface {
+	Difficulty() string
+	Features() []string
+}
+
+// TemplateInfo describes a template available to the builder
+type TemplateInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Difficulty  string   `json:"difficulty,omitempty"`
+	Features    []string `json:"features,omitempty"`
+}
+
+// DescribeTemplate returns the catalogue information of a template
+func DescribeTemplate(t Template) TemplateInfo {
+	info := TemplateInfo{
+		ID:          t.ID(),
+		Name:        t.Name(),
+		Description: t.Description(),
+		Category:    t.Category(),
+	}
+	if details, ok := t.(TemplateDetails); ok {
+		info.Difficulty = details.Difficulty()
+		info.Features = details.Features()
+	}
+	return info
+}
+
+// Registry holds the templates known to a builder
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+	order     []string
+}
+
+// NewRegistry creates an empty template registry
+func NewRegistry() *Registry {
+	return &Registry{
+		templates: map[string]Template{},
+	}
+}
+
+// Register adds a template to the registry
+func (r *Registry) Register(t Template) error {
+	id := t.ID()
+	if id == "" {
+		return fmt.Errorf("template ID is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[id]; exists {
+		return fmt.Errorf("template already registered: %s", id)
+	}
+	r.templates[id] = t
+	r.order = append(r.order, id)
+	return nil
+}
+
+// Lookup returns the template registered under id
+func (r *Registry) Lookup(id string) (Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.templates[id]
+	return t, ok
+}
+
+// Templates returns all registered templates in registration order
+func (r *Registry) Templates() []Template {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]Template, 0, len(r.order))
+	for _, id := range r.order {
+		templates = append(templates, r.templates[id])
+	}
+	return templates
+}
+
+// IDs returns the identifiers of all registered templates in registration order
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// List returns catalogue information for all registered templates
+func (r *Registry) List() []TemplateInfo {
+	templates := r.Templates()
+	infos := make([]TemplateInfo, 0, len(templates))
+	for _, t := range templates {
+		infos = append(infos, DescribeTemplate(t))
+	}
+	return infos
+}
+
+// defaultRegistry holds the built-in templates and any registered by other packages
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the registry used by NewBuilder
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a template to the default registry
+func Register(t Template) error {
+	return defaultRegistry.Register(t)
+}
+
+// MustRegister adds a template to the default registry and panics on error
+func MustRegister(t Template) {
+	if err := Register(t); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,299 @@
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// SignatureManifestFile lists the hash of every file in a signed package
+	SignatureManifestFile = "META-INF/signature.json"
+	// SignatureFile holds the base64 Ed25519 signature of SignatureManifestFile
+	SignatureFile = "META-INF/signature.sig"
+	// signatureAlgorithm is the only supported signature algorithm
+	signatureAlgorithm = "ed25519"
+)
+
+// SignatureManifest is the signed list of package files and their SHA256 hashes
+type SignatureManifest struct {
+	Version   int               `json:"version"`
+	Algorithm string            `json:"algorithm"`
+	PublicKey string            `json:"public_key"`
+	Files     map[string]string `json:"files"`
+}
+
+// GenerateSigningKey generates a new Ed25519 key pair
+func GenerateSigningKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// EncodePrivateKeyPEM encodes a private key as a PKCS#8 PEM block
+func EncodePrivateKeyPEM(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublicKeyPEM encodes a public key as a PKIX PEM block
+func EncodePublicKeyPEM(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadPrivateKey reads a PKCS#8 PEM encoded Ed25519 private key
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an Ed25519 key", path)
+	}
+	return privateKey, nil
+}
+
+// LoadPublicKey reads a PKIX PEM encoded Ed25519 public key
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", path)
+	}
+	return publicKey, nil
+}
+
+// readPEM reads the first PEM block of the given type from a file
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s does not contain a %s PEM block", path, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// SignPackage adds a signature manifest and detached signature to a package,
+// replacing any previous signature. It returns the base64 signature.
+func SignPackage(zipPath string, key ed25519.PrivateKey) (string, error) {
+	archive, signature, err := signArchive(zipPath, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace the original only once the signed archive is complete
+	tempFile, err := os.CreateTemp(filepath.Dir(zipPath), ".sign-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer func(path string) {
+		_ = os.Remove(path)
+	}(tempFile.Name())
+	if _, err := tempFile.Write(archive); err != nil {
+		_ = tempFile.Close()
+		return "", err
+	}
+	if err := tempFile.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tempFile.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tempFile.Name(), zipPath); err != nil {
+		return "", fmt.Errorf("failed to replace package: %w", err)
+	}
+
+	return signature, nil
+}
+
+// signArchive returns a copy of the package with signature entries added, and the signature
+func signArchive(zipPath string, key ed25519.PrivateKey) ([]byte, string, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func(reader *zip.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	manifest := SignatureManifest{
+		Version:   1,
+		Algorithm: signatureAlgorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Files:     map[string]string{},
+	}
+	var entries []*zip.File
+	var modified time.Time
+	signatureDir := path.Dir(SignatureFile)
+	hasSignatureDir := false
+	for _, file := range reader.File {
+		if file.Name == SignatureManifestFile || file.Name == SignatureFile {
+			continue
+		}
+		entries = append(entries, file)
+		if file.Name == signatureDir+"/" {
+			hasSignatureDir = true
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if file.Modified.After(modified) {
+			modified = file.Modified
+		}
+		entryHash, err := hashZipEntry(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to hash %s: %w", file.Name, err)
+		}
+		manifest.Files[file.Name] = entryHash
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestJSON))
+
+	// Copy entries without recompressing them
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range entries {
+		if err := zipWriter.Copy(file); err != nil {
+			return nil, "", fmt.Errorf("failed to copy %s: %w", file.Name, err)
+		}
+	}
+	// Add the directory entry as createZip does, unless an earlier signature left one
+	if !hasSignatureDir {
+		header := &zip.FileHeader{Name: signatureDir + "/", Method: zip.Store, Modified: modified}
+		header.SetMode(fs.ModeDir | 0755)
+		if _, err := zipWriter.CreateHeader(header); err != nil {
+			return nil, "", fmt.Errorf("failed to create zip directory %s: %w", signatureDir, err)
+		}
+	}
+	signatureFiles := []struct {
+		name    string
+		content []byte
+	}{
+		{SignatureManifestFile, manifestJSON},
+		{SignatureFile, []byte(signature)},
+	}
+	for _, signatureFile := range signatureFiles {
+		header := &zip.FileHeader{Name: signatureFile.name, Method: zip.Deflate, Modified: modified}
+		header.SetMode(0644)
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create zip entry %s: %w", signatureFile.name, err)
+		}
+		if _, err := entryWriter.Write(signatureFile.content); err != nil {
+			return nil, "", fmt.Errorf("failed to write zip entry %s: %w", signatureFile.name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), signature, nil
+}
+
+// VerifyPackage checks the signature of a package against a trusted public key
+// and that every file in the package matches the signed hashes
+func VerifyPackage(zipPath string, key ed25519.PublicKey) (*SignatureManifest, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func(reader *zip.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	var manifestJSON, signature []byte
+	for _, file := range reader.File {
+		switch file.Name {
+		case SignatureManifestFile:
+			manifestJSON, err = readEntry(file, DefaultMaxPackageSize)
+		case SignatureFile:
+			signature, err = readEntry(file, DefaultMaxPackageSize)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+	}
+	if manifestJSON == nil || signature == nil {
+		return nil, fmt.Errorf("package is not signed")
+	}
+
+	rawSignature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+	if !ed25519.Verify(key, manifestJSON, rawSignature) {
+		return nil, fmt.Errorf("signature does not match the trusted public key")
+	}
+
+	var manifest SignatureManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("malformed signature manifest: %w", err)
+	}
+	if manifest.Algorithm != signatureAlgorithm {
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", manifest.Algorithm)
+	}
+
+	seen := map[string]bool{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || file.Name == SignatureManifestFile || file.Name == SignatureFile {
+			continue
+		}
+		want, ok := manifest.Files[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("file %s is not covered by the signature", file.Name)
+		}
+		if seen[file.Name] {
+			return nil, fmt.Errorf("file %s appears more than once", file.Name)
+		}
+		seen[file.Name] = true
+		got, err := hashZipEntry(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", file.Name, err)
+		}
+		if got != want {
+			return nil, fmt.Errorf("file %s has been modified", file.Name)
+		}
+	}
+	for name := range manifest.Files {
+		if !seen[name] {
+			return nil, fmt.Errorf("signed file %s is missing from the package", name)
+		}
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,118 @@
+package builder
+
+import (
+	"archive/zip"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplatesCannotReadSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, templateDescriptorFile), `{"id": "leaky"}`)
+	writeTestFile(t, filepath.Join(dir, "index.html.tmpl"), `<html><body>{{printf "%x" .SigningKey}}</body></html>`)
+	tmpl, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	if err := registry.Register(tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	_, key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewBuilderWithRegistry(registry).Build(BuildOptions{
+		Name:       "Leaky",
+		Template:   "leaky",
+		OutputPath: t.TempDir(),
+		SigningKey: key,
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	index := readZipEntry(t, result.ZipPath, "index.html")
+	if want := "<html><body></body></html>"; index != want {
+		t.Errorf("index.html = %q, want %q", index, want)
+	}
+	if strings.Contains(index, hex.EncodeToString(key.Seed())) {
+		t.Error("index.html contains the signing key")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readZipEntry(t *testing.T, zipPath, name string) string {
+	t.Helper()
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	entry, err := reader.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestSignPackageAddsSignatureDirectory(t *testing.T) {
+	public, key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewBuilder().Build(BuildOptions{
+		Name:       "Signed",
+		Template:   "simple",
+		OutputPath: t.TempDir(),
+		SigningKey: key,
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	// Signing again replaces the signature and must not repeat the directory
+	if _, err := SignPackage(result.ZipPath, key); err != nil {
+		t.Fatalf("SignPackage() error = %v", err)
+	}
+	if _, err := VerifyPackage(result.ZipPath, public); err != nil {
+		t.Fatalf("VerifyPackage() error = %v", err)
+	}
+
+	reader, err := zip.OpenReader(result.ZipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	var dirs []string
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			dirs = append(dirs, file.Name)
+			if file.Method != zip.Store || file.Mode() != fs.ModeDir|0755 {
+				t.Errorf("%s: method %d, mode %v", file.Name, file.Method, file.Mode())
+			}
+		}
+	}
+	if want := []string{"META-INF/"}; !equalStrings(dirs, want) {
+		t.Errorf("directories = %v, want %v", dirs, want)
+	}
+}
@@ -0,0 +1,138 @@
+package builder
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenOptions contains characters that must be escaped in the generated HTML
+var goldenOptions = BuildOptions{
+	Name:        `Golden <Face> & "Friends"`,
+	Version:     "1.2.3",
+	Author:      "Golden Tester",
+	Description: "Pinned output for golden tests",
+	Tags:        []string{"golden", "test"},
+	CustomHTML:  "<!DOCTYPE html>\n<title>Custom</title>\n",
+	CustomCSS:   "body { width: 100%; }\n",
+	CustomJS:    "console.log(10 % 3);\n",
+}
+
+// goldenCases are rendered for every registered template, each into testdata/golden/<template><suffix>
+var goldenCases = []struct {
+	suffix  string
+	devices []string
+	theme   Theme
+	locales []string
+}{
+	{suffix: ""},
+	{suffix: "-round-454", devices: []string{"round-454"}},
+	{suffix: "-themed", theme: Theme{
+		Background: [2]string{"#000", "rgb(20, 20, 40)"},
+		Foreground: "rgba(255, 255, 255, 0.9)",
+		FontFamily: `"Fira Sans", sans-serif`,
+		SecondHand: HandStyle{Width: 1, Color: "orange"},
+		Markers:    MarkersNumerals,
+	}},
+	{suffix: "-locales", locales: []string{"en-US", "de-DE"}},
+}
+
+func TestTemplatesGolden(t *testing.T) {
+	for _, tmpl := range DefaultRegistry().Templates() {
+		for _, tc := range goldenCases {
+			options := goldenOptions
+			options.Devices = tc.devices
+			options.Theme = tc.theme
+			options.Locales = tc.locales
+			testTemplateGolden(t, tmpl, tmpl.ID()+tc.suffix, options)
+		}
+	}
+}
+
+func testTemplateGolden(t *testing.T, tmpl Template, name string, options BuildOptions) {
+	t.Run(name, func(t *testing.T) {
+		files, err := tmpl.Generate(options)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		goldenDir := filepath.Join("testdata", "golden", name)
+		if *update {
+			if err := os.RemoveAll(goldenDir); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range files {
+				goldenPath := filepath.Join(goldenDir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, content, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		want := readGoldenDir(t, goldenDir)
+		if got, wantNames := sortedKeys(files), sortedKeys(want); !equalStrings(got, wantNames) {
+			t.Fatalf("generated files = %v, golden files = %v", got, wantNames)
+		}
+		for name, content := range files {
+			if !bytes.Equal(content, want[name]) {
+				t.Errorf("%s does not match golden file; run go test ./pkg/builder -update to refresh\n got:\n%s\nwant:\n%s",
+					name, content, want[name])
+			}
+		}
+	})
+}
+
+// readGoldenDir reads every file below dir keyed by slash-separated relative path
+func readGoldenDir(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read golden files: %v", err)
+	}
+	return files
+}
+
+func sortedKeys(files map[string][]byte) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+var canvas = document.getElementById('clock');
+var theme = window.WATCHFACE_THEME;
+var ctx = canvas.getContext('2d');
+
+// Size the dial from the target device when known, otherwise from the window.
+// Round screens get a full-bleed dial; other shapes keep a margin.
+var device = window.WATCHFACE_DEVICE;
+var viewportWidth = device ? device.width : window.innerWidth;
+var viewportHeight = device ? device.height : window.innerHeight;
+var round = device && device.shape === 'round';
+var dpr = device ? device.dpr : (window.devicePixelRatio || 1);
+
+var size = Math.min(viewportWidth, viewportHeight) * (round ? 1 : 0.9);
+canvas.width = size * dpr;
+canvas.height = size * dpr;
+canvas.style.width = size + 'px';
+canvas.style.height = size + 'px';
+ctx.scale(dpr, dpr);
+
+var centerX = size / 2;
+var centerY = size / 2;
+var radius = size / 2 - (round ? 4 : 20);
+
+function drawClock() {
+    var now = new Date();
+    var hours = now.getHours() % 12;
+    var minutes = now.getMinutes();
+    var seconds = now.getSeconds();
+
+    // Clear canvas
+    ctx.clearRect(0, 0, size, size);
+
+    // Draw clock face
+    ctx.beginPath();
+    ctx.arc(centerX, centerY, radius, 0, 2 * Math.PI);
+    ctx.fillStyle = theme.dial;
+    ctx.fill();
+    ctx.strokeStyle = theme.foreground;
+    ctx.lineWidth = 2;
+    ctx.stroke();
+
+    drawMarkers();
+
+    // Draw hour hand
+    var hourAngle = ((hours + minutes / 60) * 30 - 90) * Math.PI / 180;
+    drawHand(hourAngle, theme.hourHand, theme.foreground);
+
+    // Draw minute hand
+    var minuteAngle = ((minutes + seconds / 60) * 6 - 90) * Math.PI / 180;
+    drawHand(minuteAngle, theme.minuteHand, theme.foreground);
+
+    // Draw second hand
+    var secondAngle = (seconds * 6 - 90) * Math.PI / 180;
+    drawHand(secondAngle, theme.secondHand, theme.accent);
+
+    // Draw center dot
+    ctx.beginPath();
+    ctx.arc(centerX, centerY, 8, 0, 2 * Math.PI);
+    ctx.fillStyle = theme.accent;
+    ctx.fill();
+}
+
+function drawMarkers() {
+    ctx.strokeStyle = theme.foreground;
+    ctx.fillStyle = theme.foreground;
+    ctx.lineWidth = 3;
+    ctx.lineCap = 'butt';
+    ctx.textAlign = 'center';
+    ctx.textBaseline = 'middle';
+    ctx.font = Math.round(radius * 0.16) + 'px ' + theme.fontFamily;
+
+    for (var i = 0; i < 12; i++) {
+        var angle = (i * 30 - 90) * Math.PI / 180;
+        var cos = Math.cos(angle);
+        var sin = Math.sin(angle);
+
+        if (theme.markers === 'lines') {
+            ctx.beginPath();
+            ctx.moveTo(centerX + cos * (radius - 15), centerY + sin * (radius - 15));
+            ctx.lineTo(centerX + cos * (radius - 5), centerY + sin * (radius - 5));
+            ctx.stroke();
+        } else if (theme.markers === 'dots') {
+            ctx.beginPath();
+            ctx.arc(centerX + cos * (radius - 10), centerY + sin * (radius - 10), 3, 0, 2 * Math.PI);
+            ctx.fill();
+        } else if (theme.markers === 'numerals') {
+            ctx.fillText(String(i === 0 ? 12 : i), centerX + cos * (radius * 0.82), centerY + sin * (radius * 0.82));
+        }
+    }
+}
+
+function drawHand(angle, hand, defaultColor) {
+    var length = radius * hand.length;
+    ctx.beginPath();
+    ctx.moveTo(centerX, centerY);
+    ctx.lineTo(
+        centerX + Math.cos(angle) * length,
+        centerY + Math.sin(angle) * length
+    );
+    ctx.strokeStyle = hand.color || defaultColor;
+    ctx.lineWidth = hand.width;
+    ctx.lineCap = 'round';
+    ctx.stroke();
+}
+
+// Update every second
+drawClock();
+setInterval(drawClock, 1000);
@@ -0,0 +1 @@
+console.log(10 % 3);
@@ -0,0 +1,30 @@
+var locale = window.watchfaceLocale;
+var period = document.getElementById('period');
+
+// Place the day period of 12-hour clocks where the locale puts it
+if (locale.periodFirst) {
+    period.parentNode.insertBefore(period, period.parentNode.firstChild);
+}
+
+function pad(value) {
+    return (value < 10 ? '0' : '') + value;
+}
+
+function updateTime() {
+    var now = new Date();
+    var dayPeriod = locale.dayPeriod(now.getHours());
+
+    // Format date with day of week
+    var dateString = locale.formatDate(now) + ' ' + locale.weekday(now.getDay());
+
+    // Update DOM
+    document.getElementById('hours').textContent = locale.hours(now.getHours());
+    document.getElementById('minutes').textContent = pad(now.getMinutes());
+    document.getElementById('seconds').textContent = pad(now.getSeconds());
+    period.textContent = dayPeriod && (locale.periodFirst ? dayPeriod + ' ' : ' ' + dayPeriod);
+    document.getElementById('date').textContent = dateString;
+}
+
+// Update every second
+updateTime();
+setInterval(updateTime, 1000);
@@ -0,0 +1,149 @@
+// Locale support generated by Watchface Builder. Picks the bundled locale that
+// best matches the watch's language, sets the document language and exposes
+// window.watchfaceLocale to format times and dates. Patterns use CLDR field
+// letters, see the builder's Locale type.
+(function () {
+    var LOCALES = [{"tag":"en-US","weekdays":["Sunday","Monday","Tuesday","Wednesday","Thursday","Friday","Saturday"],"shortWeekdays":["Sun","Mon","Tue","Wed","Thu","Fri","Sat"],"months":["January","February","March","April","May","June","July","August","September","October","November","December"],"shortMonths":["Jan","Feb","Mar","Apr","May","Jun","Jul","Aug","Sep","Oct","Nov","Dec"],"date":"M/d/y","monthDay":"M/d","time":"h:mm:ss a","am":"AM","pm":"PM"},{"tag":"de-DE","weekdays":["Sonntag","Montag","Dienstag","Mittwoch","Donnerstag","Freitag","Samstag"],"shortWeekdays":["So.","Mo.","Di.","Mi.","Do.","Fr.","Sa."],"months":["Januar","Februar","März","April","Mai","Juni","Juli","August","September","Oktober","November","Dezember"],"shortMonths":["Jan.","Feb.","März","Apr.","Mai","Juni","Juli","Aug.","Sept.","Okt.","Nov.","Dez."],"date":"dd.MM.y","monthDay":"dd.MM.","time":"HH:mm:ss","am":"AM","pm":"PM"}];
+
+    // pick returns the locale matching a preferred language exactly, then by
+    // language alone, falling back to the first bundled locale
+    function pick(languages) {
+        var i, j;
+        for (i = 0; i < languages.length; i++) {
+            for (j = 0; j < LOCALES.length; j++) {
+                if (LOCALES[j].tag.toLowerCase() === languages[i].toLowerCase()) {
+                    return LOCALES[j];
+                }
+            }
+        }
+        for (i = 0; i < languages.length; i++) {
+            for (j = 0; j < LOCALES.length; j++) {
+                if (LOCALES[j].tag.split('-')[0].toLowerCase() === languages[i].split('-')[0].toLowerCase()) {
+                    return LOCALES[j];
+                }
+            }
+        }
+        return LOCALES[0];
+    }
+
+    var languages = navigator.languages && navigator.languages.length ? navigator.languages : [navigator.language || ''];
+    var locale = pick(languages);
+    document.documentElement.lang = locale.tag;
+
+    function number(value, width) {
+        var s = String(value);
+        while (s.length < width) {
+            s = '0' + s;
+        }
+        return s;
+    }
+
+    // field formats one pattern field of n repeated letters from the parts of a time
+    function field(letter, n, t) {
+        switch (letter) {
+        case 'y':
+            return number(t.year, n);
+        case 'M':
+            if (n >= 4) {
+                return locale.months[t.month];
+            }
+            return n === 3 ? locale.shortMonths[t.month] : number(t.month + 1, n);
+        case 'd':
+            return number(t.day, n);
+        case 'E':
+            return n >= 4 ? locale.weekdays[t.weekday] : locale.shortWeekdays[t.weekday];
+        case 'H':
+            return number(t.hours, n);
+        case 'h':
+            return number((t.hours + 11) % 12 + 1, n);
+        case 'm':
+            return number(t.minutes, n);
+        case 's':
+            return number(t.seconds, n);
+        case 'a':
+            return t.hours < 12 ? locale.am : locale.pm;
+        }
+        return new Array(n + 1).join(letter);
+    }
+
+    function format(pattern, t) {
+        var out = '';
+        var i = 0;
+        while (i < pattern.length) {
+            var c = pattern.charAt(i);
+            if (c === '\'') {
+                var end = pattern.indexOf('\'', i + 1);
+                if (end < 0) {
+                    end = pattern.length;
+                }
+                out += pattern.substring(i + 1, end);
+                i = end + 1;
+            } else if (/[a-zA-Z]/.test(c)) {
+                var n = 1;
+                while (pattern.charAt(i + n) === c) {
+                    n++;
+                }
+                out += field(c, n, t);
+                i += n;
+            } else {
+                out += c;
+                i++;
+            }
+        }
+        return out;
+    }
+
+    // parts returns the fields of a Date in local time, or in UTC for dates
+    // already shifted to another zone
+    function parts(date, utc) {
+        return utc ? {
+            year: date.getUTCFullYear(), month: date.getUTCMonth(), day: date.getUTCDate(), weekday: date.getUTCDay(),
+            hours: date.getUTCHours(), minutes: date.getUTCMinutes(), seconds: date.getUTCSeconds()
+        } : {
+            year: date.getFullYear(), month: date.getMonth(), day: date.getDate(), weekday: date.getDay(),
+            hours: date.getHours(), minutes: date.getMinutes(), seconds: date.getSeconds()
+        };
+    }
+
+    var timeWithoutSeconds = locale.time.replace(':ss', '').replace(':s', '');
+
+    window.watchfaceLocale = {
+        tag: locale.tag,
+        hour12: locale.time.indexOf('h') >= 0,
+        // periodFirst is true where the day period comes before the hours
+        periodFirst: locale.time.indexOf('a') >= 0 && locale.time.indexOf('a') < locale.time.search(/[hH]/),
+        format: function (pattern, date, utc) {
+            return format(pattern, parts(date, utc));
+        },
+        formatDate: function (date, utc) {
+            return format(locale.date, parts(date, utc));
+        },
+        formatMonthDay: function (date, utc) {
+            return format(locale.monthDay, parts(date, utc));
+        },
+        formatTime: function (date, seconds, utc) {
+            return format(seconds ? locale.time : timeWithoutSeconds, parts(date, utc));
+        },
+        // hours formats the hour field of the locale's time pattern
+        hours: function (hours) {
+            var match = /h+|H+/.exec(locale.time);
+            return field(match[0].charAt(0), match[0].length, {hours: hours});
+        },
+        // dayPeriod returns AM or PM on a 12-hour clock, otherwise an empty string
+        dayPeriod: function (hours) {
+            return locale.time.indexOf('a') >= 0 ? (hours < 12 ? locale.am : locale.pm) : '';
+        },
+        weekday: function (day) {
+            return locale.weekdays[day];
+        },
+        shortWeekday: function (day) {
+            return locale.shortWeekdays[day];
+        },
+        month: function (month) {
+            return locale.months[month];
+        },
+        shortMonth: function (month) {
+            return locale.shortMonths[month];
+        }
+    };
+})();
@@ -0,0 +1,17 @@
+var locale = window.watchfaceLocale;
+
+function updateTime() {
+    var now = new Date();
+
+    // Format time and date for the watch's locale
+    var timeString = locale.formatTime(now, true);
+    var dateString = locale.formatDate(now);
+
+    // Update DOM
+    document.getElementById('time').textContent = timeString;
+    document.getElementById('date').textContent = dateString;
+}
+
+// Update every second
+updateTime();
+setInterval(updateTime, 1000);
@@ -0,0 +1,324 @@
+package builder
+
+import (
+	"fmt"
+	"html/template"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Theme holds the visual parameters of a face. Zero fields take the template's
+// defaults, see DefaultTheme.
+type Theme struct {
+	Background [2]string `json:"background" yaml:"background,flow"`    // Gradient from top left to bottom right
+	Foreground string    `json:"foreground" yaml:"foreground"`         // Text, dial ink and hour hand
+	Accent     string    `json:"accent" yaml:"accent"`                 // Second hand, centre dot and secondary text
+	Dial       string    `json:"dial,omitempty" yaml:"dial,omitempty"` // Analog dial fill
+	FontFamily string    `json:"fontFamily" yaml:"fontFamily"`
+	HourHand   HandStyle `json:"hourHand" yaml:"hourHand"`
+	MinuteHand HandStyle `json:"minuteHand" yaml:"minuteHand"`
+	SecondHand HandStyle `json:"secondHand" yaml:"secondHand"`
+	Markers    string    `json:"markers" yaml:"markers"` // Analog hour markers, see MarkerStyles
+}
+
+// HandStyle describes an analog clock hand
+type HandStyle struct {
+	Width  float64 `json:"width" yaml:"width"`                     // In CSS pixels
+	Length float64 `json:"length" yaml:"length"`                   // Fraction of the dial radius
+	Color  string  `json:"color,omitempty" yaml:"color,omitempty"` // Defaults to the foreground, or the accent for the second hand
+}
+
+// Marker styles of the analog template
+const (
+	MarkersLines    = "lines"
+	MarkersDots     = "dots"
+	MarkersNumerals = "numerals"
+	MarkersNone     = "none"
+)
+
+var markerStyles = []string{MarkersLines, MarkersDots, MarkersNumerals, MarkersNone}
+
+// MarkerStyles returns the names of all marker styles
+func MarkerStyles() []string {
+	return append([]string(nil), markerStyles...)
+}
+
+const systemFonts = "-apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif"
+
+// analogHands are the hands of the analog template, shared by every theme
+var analogHands = struct{ hour, minute, second HandStyle }{
+	hour:   HandStyle{Width: 6, Length: 0.5},
+	minute: HandStyle{Width: 4, Length: 0.7, Color: "#666666"},
+	second: HandStyle{Width: 2, Length: 0.8},
+}
+
+// builtinThemes are the default themes of the built-in templates
+var builtinThemes = map[string]Theme{
+	"simple": {
+		Background: [2]string{"#667eea", "#764ba2"},
+		Foreground: "#ffffff",
+		Accent:     "#ffffff",
+		FontFamily: systemFonts,
+	},
+	"analog": {
+		Background: [2]string{"#f5f5f5", "#e0e0e0"},
+		Foreground: "#333333",
+		Accent:     "#e74c3c",
+		Dial:       "#ffffff",
+		FontFamily: systemFonts,
+	},
+	"digital": {
+		Background: [2]string{"#0a0a1e", "#1e1e3c"},
+		Foreground: "#00ffff",
+		Accent:     "#00cccc",
+		FontFamily: "'Courier New', monospace",
+	},
+}
+
+// DefaultTheme returns the default theme of a template. Templates without their
+// own theme share the simple template's.
+func DefaultTheme(templateID string) Theme {
+	theme, ok := builtinThemes[templateID]
+	if !ok {
+		theme = builtinThemes["simple"]
+	}
+	if theme.Dial == "" {
+		theme.Dial = "#ffffff"
+	}
+	theme.HourHand = analogHands.hour
+	theme.MinuteHand = analogHands.minute
+	theme.SecondHand = analogHands.second
+	theme.Markers = MarkersLines
+	return theme
+}
+
+// ResolvedTheme returns the template's default theme with the options' overrides
+// applied, and a bundled font in front of the font family list
+func (o BuildOptions) ResolvedTheme() Theme {
+	theme := DefaultTheme(o.Template).Merge(o.Theme)
+	if len(o.Font) > 0 {
+		theme.FontFamily = "'" + FontFamilyName + "', " + theme.FontFamily
+	}
+	return theme
+}
+
+// Merge returns the theme with the non-zero fields of overrides applied
+func (t Theme) Merge(overrides Theme) Theme {
+	if overrides.Background[0] != "" || overrides.Background[1] != "" {
+		t.Background = overrides.Background
+	}
+	mergeString(&t.Foreground, overrides.Foreground)
+	mergeString(&t.Accent, overrides.Accent)
+	mergeString(&t.Dial, overrides.Dial)
+	mergeString(&t.FontFamily, overrides.FontFamily)
+	mergeString(&t.Markers, overrides.Markers)
+	t.HourHand = t.HourHand.merge(overrides.HourHand)
+	t.MinuteHand = t.MinuteHand.merge(overrides.MinuteHand)
+	t.SecondHand = t.SecondHand.merge(overrides.SecondHand)
+	return t
+}
+
+func (h HandStyle) merge(overrides HandStyle) HandStyle {
+	if overrides.Width != 0 {
+		h.Width = overrides.Width
+	}
+	if overrides.Length != 0 {
+		h.Length = overrides.Length
+	}
+	mergeString(&h.Color, overrides.Color)
+	return h
+}
+
+func mergeString(field *string, override string) {
+	if override != "" {
+		*field = override
+	}
+}
+
+// Validate checks that every value of a resolved theme is safe to place in CSS
+// and can be drawn by the preview renderer
+func (t Theme) Validate() error {
+	colors := []struct{ name, value string }{
+		{"background", t.Background[0]},
+		{"background", t.Background[1]},
+		{"foreground", t.Foreground},
+		{"accent", t.Accent},
+		{"dial", t.Dial},
+	}
+	for _, c := range colors {
+		if _, err := parseCSSColor(c.value); err != nil {
+			return fmt.Errorf("theme %s: %v", c.name, err)
+		}
+	}
+	if !fontFamilyPattern.MatchString(t.FontFamily) {
+		return fmt.Errorf("theme font family: invalid value %q", t.FontFamily)
+	}
+	if !contains(markerStyles, t.Markers) {
+		return fmt.Errorf("theme markers: unknown style %q (expected one of %s)", t.Markers, strings.Join(markerStyles, ", "))
+	}
+
+	hands := []struct {
+		name string
+		hand HandStyle
+	}{{"hour hand", t.HourHand}, {"minute hand", t.MinuteHand}, {"second hand", t.SecondHand}}
+	for _, h := range hands {
+		if h.hand.Width <= 0 || h.hand.Width > 20 {
+			return fmt.Errorf("theme %s: width must be between 0 and 20, got %g", h.name, h.hand.Width)
+		}
+		if h.hand.Length <= 0 || h.hand.Length > 1 {
+			return fmt.Errorf("theme %s: length must be between 0 and 1, got %g", h.name, h.hand.Length)
+		}
+		if h.hand.Color != "" {
+			if _, err := parseCSSColor(h.hand.Color); err != nil {
+				return fmt.Errorf("theme %s: %v", h.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fontFamilyPattern matches font family lists such as "'Courier New', monospace",
+// each family a name of words or a name quoted with matching quotes, so that the
+// value cannot open a string that runs on through the rest of the stylesheet
+var fontFamilyPattern = regexp.MustCompile(`^` + fontFamilyName + `(?:,` + fontFamilyName + `)*$`)
+
+const fontFamilyName = ` *(?:'[A-Za-z0-9 -]+'|"[A-Za-z0-9 -]+"|[A-Za-z0-9-]+(?: +[A-Za-z0-9-]+)*) *`
+
+// cssColorFunc matches rgb() and rgba() colours
+var cssColorFunc = regexp.MustCompile(`^rgba?\(\s*([0-9]{1,3})\s*,\s*([0-9]{1,3})\s*,\s*([0-9]{1,3})\s*(?:,\s*([0-9]*\.?[0-9]+)\s*)?\)$`)
+
+// namedColors are the CSS basic colour keywords
+var namedColors = map[string]color.NRGBA{
+	"black": {0, 0, 0, 255}, "silver": {192, 192, 192, 255}, "gray": {128, 128, 128, 255},
+	"white": {255, 255, 255, 255}, "maroon": {128, 0, 0, 255}, "red": {255, 0, 0, 255},
+	"purple": {128, 0, 128, 255}, "fuchsia": {255, 0, 255, 255}, "green": {0, 128, 0, 255},
+	"lime": {0, 255, 0, 255}, "olive": {128, 128, 0, 255}, "yellow": {255, 255, 0, 255},
+	"navy": {0, 0, 128, 255}, "blue": {0, 0, 255, 255}, "teal": {0, 128, 128, 255},
+	"aqua": {0, 255, 255, 255}, "orange": {255, 165, 0, 255}, "transparent": {0, 0, 0, 0},
+}
+
+// parseCSSColor parses a hex (#rgb, #rgba, #rrggbb, #rrggbbaa), rgb(), rgba() or
+// basic named CSS colour
+func parseCSSColor(value string) (color.NRGBA, error) {
+	value = strings.TrimSpace(value)
+	if c, ok := namedColors[strings.ToLower(value)]; ok {
+		return c, nil
+	}
+
+	if hex := strings.TrimPrefix(value, "#"); hex != value {
+		if len(hex) == 3 || len(hex) == 4 {
+			var expanded strings.Builder
+			for _, r := range hex {
+				expanded.WriteString(strings.Repeat(string(r), 2))
+			}
+			hex = expanded.String()
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		if n, err := strconv.ParseUint(hex, 16, 32); err == nil && len(hex) == 8 {
+			return color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+		}
+	}
+
+	if m := cssColorFunc.FindStringSubmatch(value); m != nil {
+		var channels [3]uint8
+		for i := range channels {
+			n, _ := strconv.Atoi(m[i+1])
+			if n > 255 {
+				return color.NRGBA{}, fmt.Errorf("invalid colour %q", value)
+			}
+			channels[i] = uint8(n)
+		}
+		alpha := 1.0
+		if m[4] != "" {
+			alpha, _ = strconv.ParseFloat(m[4], 64)
+		}
+		if alpha <= 1 {
+			return color.NRGBA{channels[0], channels[1], channels[2], uint8(alpha*255 + 0.5)}, nil
+		}
+	}
+	return color.NRGBA{}, fmt.Errorf("invalid colour %q", value)
+}
+
+// themeColor parses a colour of a validated theme, falling back to the fallback
+// colour for values the renderer cannot draw
+func themeColor(value string, fallback color.Color) color.Color {
+	c, err := parseCSSColor(value)
+	if err != nil {
+		return fallback
+	}
+	return c
+}
+
+// cssColor marks a theme colour as safe CSS for html/template, after checking it
+func cssColor(value string) (template.CSS, error) {
+	if _, err := parseCSSColor(value); err != nil {
+		return "", err
+	}
+	return template.CSS(value), nil
+}
+
+// cssFontFamily marks a theme font family as safe CSS for html/template, after checking it
+func cssFontFamily(value string) (template.CSS, error) {
+	if !fontFamilyPattern.MatchString(value) {
+		return "", fmt.Errorf("invalid font family %q", value)
+	}
+	return template.CSS(value), nil
+}
+
+// ParseBackground parses a "from,to" gradient such as "#000000,rgb(20, 20, 40)".
+// A single colour gives a flat background.
+func ParseBackground(spec string) ([2]string, error) {
+	parts := splitCSSList(spec)
+	switch len(parts) {
+	case 1:
+		return [2]string{parts[0], parts[0]}, nil
+	case 2:
+		return [2]string{parts[0], parts[1]}, nil
+	}
+	return [2]string{}, fmt.Errorf("invalid background %q (expected from,to)", spec)
+}
+
+// ParseHandStyle parses a "width,length[,color]" hand such as "2,0.8,#e74c3c"
+func ParseHandStyle(spec string) (HandStyle, error) {
+	parts := splitCSSList(spec)
+	if len(parts) < 2 || len(parts) > 3 {
+		return HandStyle{}, fmt.Errorf("invalid hand %q (expected width,length[,color])", spec)
+	}
+	width, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return HandStyle{}, fmt.Errorf("invalid hand width %q", parts[0])
+	}
+	length, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return HandStyle{}, fmt.Errorf("invalid hand length %q", parts[1])
+	}
+	hand := HandStyle{Width: width, Length: length}
+	if len(parts) == 3 {
+		hand.Color = parts[2]
+	}
+	return hand, nil
+}
+
+// splitCSSList splits a comma-separated list, leaving commas inside rgb() intact
+func splitCSSList(spec string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range spec {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(spec[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(spec[start:]))
+}
@@ -0,0 +1,43 @@
+package builder
+
+import "testing"
+
+func TestFontFamilyPattern(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{systemFonts, true},
+		{"'Courier New', monospace", true},
+		{`"Fira Sans",sans-serif`, true},
+		{"Noto Sans SC , serif", true},
+		{"'WatchfaceFont', 'Segoe UI', -apple-system", true},
+		{"", false},
+		{"'Courier New", false},
+		{"Courier New'", false},
+		{`'Courier New", monospace`, false},
+		{`"It's", serif`, false},
+		{"'a' 'b'", false},
+		{"serif,", false},
+		{", serif", false},
+		{"serif;} body{color:red", false},
+		{"'</style>'", false},
+	}
+	for _, tt := range tests {
+		if got := fontFamilyPattern.MatchString(tt.value); got != tt.valid {
+			t.Errorf("fontFamilyPattern.MatchString(%q) = %v, want %v", tt.value, got, tt.valid)
+		}
+	}
+
+	theme := BuildOptions{Template: "digital"}.ResolvedTheme()
+	if err := theme.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	theme.FontFamily = "'Courier New"
+	if err := theme.Validate(); err == nil {
+		t.Error("Validate() accepted an unbalanced quote")
+	}
+	if _, err := cssFontFamily(`"x`); err == nil {
+		t.Error("cssFontFamily() accepted an unbalanced quote")
+	}
+}
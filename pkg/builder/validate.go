@@ -0,0 +1,332 @@
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultMaxPackageSize is the default limit on the uncompressed size of a package
+const DefaultMaxPackageSize = 10 << 20
+
+// Severity is the severity of a validation issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// ValidateOptions contains options for validating a package
+type ValidateOptions struct {
+	MaxTotalSize int64 // Maximum uncompressed size of all entries, 0 uses DefaultMaxPackageSize
+	AllowRemote  bool  // Whether references to remote URLs are allowed
+}
+
+// Issue is a single problem found in a package
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	File     string   `json:"file,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// ValidationReport is the result of validating a package
+type ValidationReport struct {
+	Package   string        `json:"package"`
+	Valid     bool          `json:"valid"`
+	Manifest  *ManifestData `json:"manifest,omitempty"`
+	FileCount int           `json:"file_count"`
+	TotalSize int64         `json:"total_size"`
+	Issues    []Issue       `json:"issues"`
+}
+
+// Errors returns the number of error issues
+func (r *ValidationReport) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of warning issues
+func (r *ValidationReport) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+func (r *ValidationReport) count(severity Severity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *ValidationReport) addError(code, file, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{SeverityError, code, file, fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(code, file, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{SeverityWarning, code, file, fmt.Sprintf(format, args...)})
+}
+
+var (
+	// semverPattern matches MAJOR.MINOR.PATCH versions with optional suffixes
+	semverPattern = regexp.MustCompile(`^\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?$`)
+	// cssURLPattern matches url(...) references in stylesheets
+	cssURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+	// cssImportPattern matches @import "..." references in stylesheets
+	cssImportPattern = regexp.MustCompile(`@import\s+['"]([^'"]+)['"]`)
+	// jsRemotePattern matches remote URLs in script string literals
+	jsRemotePattern = regexp.MustCompile(`['"\x60]((?:https?:|wss?:)?//[^'"\x60\s]+)`)
+)
+
+// ValidatePackage checks a watchface ZIP against the package specification
+func ValidatePackage(zipPath string, options ValidateOptions) (*ValidationReport, error) {
+	if options.MaxTotalSize <= 0 {
+		options.MaxTotalSize = DefaultMaxPackageSize
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func(reader *zip.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	report := &ValidationReport{Package: zipPath, Issues: []Issue{}}
+
+	// Index entries and check paths and sizes
+	entries := map[string]*zip.File{}
+	for _, file := range reader.File {
+		if strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		if err := checkEntryPath(file.Name); err != nil {
+			report.addError("unsafe_path", file.Name, "%v", err)
+			continue
+		}
+		if _, exists := entries[file.Name]; exists {
+			report.addError("duplicate_entry", file.Name, "entry appears more than once")
+			continue
+		}
+		entries[file.Name] = file
+		report.FileCount++
+		report.TotalSize += int64(file.UncompressedSize64)
+	}
+	if report.TotalSize > options.MaxTotalSize {
+		report.addError("size_limit", "", "uncompressed size %d bytes exceeds limit of %d bytes",
+			report.TotalSize, options.MaxTotalSize)
+	}
+
+	// Manifest
+	manifestFile, ok := entries["manifest.json"]
+	if !ok {
+		report.addError("manifest_missing", "manifest.json", "package has no manifest.json")
+	} else if content, err := readEntry(manifestFile, options.MaxTotalSize); err != nil {
+		report.addError("manifest_unreadable", "manifest.json", "%v", err)
+	} else {
+		report.Manifest = validateManifest(report, content)
+	}
+
+	// Entrypoint and the files it references
+	entrypoint := "index.html"
+	if report.Manifest != nil && report.Manifest.Entrypoint != "" {
+		entrypoint = report.Manifest.Entrypoint
+	}
+	if entry, ok := entries[entrypoint]; !ok {
+		report.addError("entrypoint_missing", entrypoint, "entrypoint does not exist in the package")
+	} else if content, err := readEntry(entry, options.MaxTotalSize); err != nil {
+		report.addError("entrypoint_unreadable", entrypoint, "%v", err)
+	} else {
+		for _, ref := range htmlReferences(content) {
+			checkReference(report, entries, entrypoint, ref, options)
+		}
+	}
+
+	// Stylesheets and scripts
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := entries[name]
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".css" && ext != ".js" {
+			continue
+		}
+		content, err := readEntry(entry, options.MaxTotalSize)
+		if err != nil {
+			report.addError("file_unreadable", name, "%v", err)
+			continue
+		}
+		if ext == ".css" {
+			for _, ref := range cssReferences(content) {
+				checkReference(report, entries, name, ref, options)
+			}
+		} else if !options.AllowRemote {
+			for _, match := range jsRemotePattern.FindAllSubmatch(content, -1) {
+				report.addWarning("remote_url", name, "script references remote URL %s", match[1])
+			}
+		}
+	}
+
+	// Preview image
+	if entry, ok := entries["preview.png"]; !ok {
+		report.addWarning("preview_missing", "preview.png", "package has no preview image")
+	} else if content, err := readEntry(entry, options.MaxTotalSize); err != nil {
+		report.addError("preview_unreadable", "preview.png", "%v", err)
+	} else if _, err := png.DecodeConfig(bytes.NewReader(content)); err != nil {
+		report.addError("preview_invalid", "preview.png", "preview is not a valid PNG: %v", err)
+	}
+
+	report.Valid = report.Errors() == 0
+	return report, nil
+}
+
+// validateManifest parses manifest.json and checks required fields
+func validateManifest(report *ValidationReport, content []byte) *ManifestData {
+	var manifest ManifestData
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		report.addError("manifest_invalid", "manifest.json", "manifest.json does not parse: %v", err)
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ManifestData{}); err != nil {
+		report.addWarning("manifest_unknown_field", "manifest.json", "%v", err)
+	}
+
+	if manifest.Name == "" {
+		report.addError("manifest_field", "manifest.json", "name is required")
+	}
+	if manifest.Version == "" {
+		report.addError("manifest_field", "manifest.json", "version is required")
+	} else if !semverPattern.MatchString(manifest.Version) {
+		report.addWarning("manifest_version", "manifest.json", "version %q is not MAJOR.MINOR.PATCH", manifest.Version)
+	}
+	if manifest.Entrypoint == "" {
+		report.addError("manifest_field", "manifest.json", "entrypoint is required")
+	}
+	if manifest.Author == "" {
+		report.addWarning("manifest_field", "manifest.json", "author is empty")
+	}
+	return &manifest
+}
+
+// checkReference checks that a reference from file resolves to an entry in the package
+func checkReference(report *ValidationReport, entries map[string]*zip.File, file, ref string, options ValidateOptions) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		report.addError("invalid_reference", file, "cannot parse reference %q: %v", ref, err)
+		return
+	}
+	switch {
+	case u.Scheme == "data" || u.Scheme == "javascript" || u.Scheme == "about":
+		return
+	case u.Scheme != "" || u.Host != "":
+		if !options.AllowRemote {
+			report.addError("remote_url", file, "remote URL %s is not allowed", ref)
+		}
+		return
+	case strings.HasPrefix(u.Path, "/"):
+		report.addError("absolute_reference", file, "absolute reference %s will not resolve inside the package", ref)
+		return
+	}
+
+	target := path.Join(path.Dir(file), u.Path)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		report.addError("unsafe_reference", file, "reference %s points outside the package", ref)
+		return
+	}
+	if _, ok := entries[target]; !ok {
+		report.addError("missing_reference", file, "referenced file %s is not in the package", target)
+	}
+}
+
+// htmlReferences returns the resource references of an HTML document
+func htmlReferences(content []byte) []string {
+	var refs []string
+	tokenizer := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return refs
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		attrs := map[string]string{}
+		for _, attr := range token.Attr {
+			attrs[attr.Key] = attr.Val
+		}
+		switch token.Data {
+		case "link":
+			if href, ok := attrs["href"]; ok {
+				refs = append(refs, href)
+			}
+		case "script", "img", "source", "audio", "video", "iframe", "embed":
+			if src, ok := attrs["src"]; ok {
+				refs = append(refs, src)
+			}
+		}
+	}
+}
+
+// cssReferences returns the url() and @import references of a stylesheet
+func cssReferences(content []byte) []string {
+	var refs []string
+	for _, pattern := range []*regexp.Regexp{cssURLPattern, cssImportPattern} {
+		for _, match := range pattern.FindAllSubmatch(content, -1) {
+			refs = append(refs, string(match[1]))
+		}
+	}
+	return refs
+}
+
+// checkEntryPath checks that an entry name is a safe relative path
+func checkEntryPath(name string) error {
+	if strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return fmt.Errorf("entry path must be relative and slash-separated")
+	}
+	if cleaned := path.Clean(name); cleaned != name || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("entry path must be clean and stay inside the package")
+	}
+	return nil
+}
+
+// readEntry reads a ZIP entry, refusing entries larger than limit
+func readEntry(file *zip.File, limit int64) ([]byte, error) {
+	if int64(file.UncompressedSize64) > limit {
+		return nil, fmt.Errorf("entry exceeds %d bytes", limit)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func(rc io.ReadCloser) {
+		_ = rc.Close()
+	}(rc)
+
+	return io.ReadAll(io.LimitReader(rc, limit+1))
+}
@@ -0,0 +1,180 @@
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestZip writes a package with the given entries and returns its path
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "package.zip")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(out)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// validPackage returns the entries of a package without issues, with overrides
+// applied and entries overridden with "" left out
+func validPackage(t *testing.T, overrides map[string]string) map[string]string {
+	t.Helper()
+	var preview bytes.Buffer
+	if err := png.Encode(&preview, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"manifest.json": `{"name": "Valid", "version": "1.0.0", "author": "Ada", "entrypoint": "index.html"}`,
+		"index.html":    `<html><head><link rel="stylesheet" href="style.css"></head><body><script src="js/script.js"></script></body></html>`,
+		"style.css":     `body { background: url("img/bg.png"); }`,
+		"js/script.js":  `var a = 1;`,
+		"img/bg.png":    preview.String(),
+		"preview.png":   preview.String(),
+	}
+	for name, content := range overrides {
+		if content == "" {
+			delete(files, name)
+		} else {
+			files[name] = content
+		}
+	}
+	return files
+}
+
+// issueCodes returns the codes of the issues of a report with a severity
+func issueCodes(report *ValidationReport, severity Severity) []string {
+	var codes []string
+	for _, issue := range report.Issues {
+		if issue.Severity == severity {
+			codes = append(codes, issue.Code)
+		}
+	}
+	return codes
+}
+
+func TestValidatePackage(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		options   ValidateOptions
+		errors    []string
+		warnings  []string
+	}{
+		{name: "valid"},
+		{
+			name:      "missing manifest",
+			overrides: map[string]string{"manifest.json": ""},
+			errors:    []string{"manifest_missing"},
+		},
+		{
+			name:      "missing entrypoint",
+			overrides: map[string]string{"manifest.json": `{"name": "A", "version": "1.0.0", "author": "Ada", "entrypoint": "main.html"}`},
+			errors:    []string{"entrypoint_missing"},
+		},
+		{
+			name:      "missing referenced file",
+			overrides: map[string]string{"js/script.js": ""},
+			errors:    []string{"missing_reference"},
+		},
+		{
+			// Every entry fits, but not all of them together
+			name:      "oversized",
+			overrides: map[string]string{"img/photo.jpg": strings.Repeat("x", 2000)},
+			options:   ValidateOptions{MaxTotalSize: 2048},
+			errors:    []string{"size_limit"},
+		},
+		{
+			name:      "missing manifest field",
+			overrides: map[string]string{"manifest.json": `{"version": "1.0.0", "author": "Ada", "entrypoint": "index.html"}`},
+			errors:    []string{"manifest_field"},
+		},
+		{
+			name:      "bad manifest field",
+			overrides: map[string]string{"manifest.json": `{"name": "A", "version": "one", "entrypoint": "index.html", "colour": "red"}`},
+			warnings:  []string{"manifest_unknown_field", "manifest_version", "manifest_field"},
+		},
+		{
+			name:      "malformed manifest",
+			overrides: map[string]string{"manifest.json": `{"name": `},
+			errors:    []string{"manifest_invalid"},
+		},
+		{
+			name:      "remote reference",
+			overrides: map[string]string{"index.html": `<script src="https://example.com/a.js"></script>`},
+			errors:    []string{"remote_url"},
+		},
+		{
+			name:      "unsafe path",
+			overrides: map[string]string{"../escape.txt": "x"},
+			errors:    []string{"unsafe_path"},
+		},
+		{
+			name:      "missing preview",
+			overrides: map[string]string{"preview.png": ""},
+			warnings:  []string{"preview_missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ValidatePackage(writeTestZip(t, validPackage(t, tt.overrides)), tt.options)
+			if err != nil {
+				t.Fatalf("ValidatePackage() error = %v", err)
+			}
+			if errors := issueCodes(report, SeverityError); !equalStrings(errors, tt.errors) {
+				t.Errorf("errors = %v, want %v", errors, tt.errors)
+			}
+			if warnings := issueCodes(report, SeverityWarning); !equalStrings(warnings, tt.warnings) {
+				t.Errorf("warnings = %v, want %v", warnings, tt.warnings)
+			}
+			if report.Valid != (len(tt.errors) == 0) || report.Errors() != len(tt.errors) {
+				t.Errorf("Valid = %v with %d errors", report.Valid, report.Errors())
+			}
+		})
+	}
+}
+
+func TestValidatePackageReportsContents(t *testing.T) {
+	files := validPackage(t, nil)
+	report, err := ValidatePackage(writeTestZip(t, files), ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FileCount != len(files) || report.Manifest == nil || report.Manifest.Name != "Valid" {
+		t.Errorf("report = %+v", report)
+	}
+	var size int64
+	for _, content := range files {
+		size += int64(len(content))
+	}
+	if report.TotalSize != size {
+		t.Errorf("TotalSize = %d, want %d", report.TotalSize, size)
+	}
+
+	if _, err := ValidatePackage(filepath.Join(t.TempDir(), "missing.zip"), ValidateOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "failed to open package") {
+		t.Errorf("ValidatePackage() of a missing file error = %v", err)
+	}
+}
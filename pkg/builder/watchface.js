@@ -0,0 +1,110 @@
+/*! watchface.js {{.Version}} - Watchface Builder data bridge */
+// Gives faces read access to the watch's data. The watch firmware provides
+// window.WatchfaceHost.getData(type), returning the current value as a JSON
+// string, and calls watchface.dispatch(type, value) whenever a value changes.
+//
+// Data types and their values:
+//   battery        {level: 0-100, charging: boolean}
+//   steps          {count: number, goal: number}
+//   heart_rate     {bpm: number, time: epoch milliseconds of the reading}
+//   weather        {temperature: number, unit: "C" | "F", condition: string,
+//                   high: number, low: number, location: string}
+//   notifications  {unread: number}
+//
+// A face can only read the types declared as permissions in its manifest.
+(function (global) {
+    var VERSION = '{{.Version}}';
+    var PERMISSIONS = {{.Permissions}};
+
+    var values = {};
+    var listeners = {};
+
+    function permitted(type) {
+        for (var i = 0; i < PERMISSIONS.length; i++) {
+            if (PERMISSIONS[i] === type) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    function check(type) {
+        if (!permitted(type)) {
+            throw new Error('watchface: "' + type + '" is not declared in the manifest permissions');
+        }
+    }
+
+    function fetchValue(type) {
+        var host = global.WatchfaceHost;
+        if (!host || typeof host.getData !== 'function') {
+            return null;
+        }
+        try {
+            var json = host.getData(type);
+            return json ? JSON.parse(json) : null;
+        } catch (e) {
+            return null;
+        }
+    }
+
+    var watchface = {
+        version: VERSION,
+        permissions: PERMISSIONS.slice(),
+
+        // has reports whether the face may read a data type
+        has: permitted,
+
+        // get returns the latest value of a data type, or null when unknown
+        get: function (type) {
+            check(type);
+            if (!(type in values)) {
+                var value = fetchValue(type);
+                if (value === null) {
+                    return null;
+                }
+                values[type] = value;
+            }
+            return values[type];
+        },
+
+        // on calls callback with every new value of a data type, starting with the
+        // current one if known, and returns a function that stops the calls
+        on: function (type, callback) {
+            check(type);
+            (listeners[type] = listeners[type] || []).push(callback);
+            var current = watchface.get(type);
+            if (current !== null) {
+                callback(current);
+            }
+            return function () {
+                var list = listeners[type] || [];
+                for (var i = 0; i < list.length; i++) {
+                    if (list[i] === callback) {
+                        list.splice(i, 1);
+                        break;
+                    }
+                }
+            };
+        },
+
+        // dispatch is called by the host when a value changes
+        dispatch: function (type, value) {
+            if (!permitted(type)) {
+                return;
+            }
+            values[type] = value;
+            var list = (listeners[type] || []).slice();
+            for (var i = 0; i < list.length; i++) {
+                list[i](value);
+            }
+        },
+
+        battery: function () { return watchface.get('battery'); },
+        steps: function () { return watchface.get('steps'); },
+        heartRate: function () { return watchface.get('heart_rate'); },
+        weather: function () { return watchface.get('weather'); },
+        notifications: function () { return watchface.get('notifications'); }
+    };
+
+    global.watchface = watchface;
+})(window);
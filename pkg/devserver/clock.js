@@ -0,0 +1,56 @@
+// Development clock shim, injected by the dev server ahead of the face's scripts.
+// It routes `new Date()` and `Date.now()` through the simulator's clock so the face
+// can be shown at any time, sped up or frozen. Packages never contain it.
+(function () {
+    var RealDate = window.Date;
+    var clock = null;
+    try {
+        clock = window.parent !== window ? window.parent.watchfaceClock : null;
+    } catch (e) {
+        // Not framed by the simulator
+    }
+    if (!clock) {
+        return;
+    }
+
+    function now() {
+        return clock.now();
+    }
+
+    function MockDate(a, b, c, d, e, f, g) {
+        if (!(this instanceof MockDate)) {
+            return new RealDate(now()).toString();
+        }
+        switch (arguments.length) {
+            case 0:
+                return new RealDate(now());
+            case 1:
+                return new RealDate(a);
+            default:
+                return new RealDate(a, b, c === undefined ? 1 : c, d || 0, e || 0, f || 0, g || 0);
+        }
+    }
+    MockDate.prototype = RealDate.prototype;
+    MockDate.now = now;
+    MockDate.parse = RealDate.parse;
+    MockDate.UTC = RealDate.UTC;
+    window.Date = MockDate;
+
+    // Timers run faster along with the clock; the simulator reloads the face
+    // whenever the speed changes
+    var speed = clock.speed();
+    if (speed !== 1) {
+        var realSetTimeout = window.setTimeout;
+        var realSetInterval = window.setInterval;
+        window.setTimeout = function (callback, delay) {
+            var args = Array.prototype.slice.call(arguments);
+            args[1] = (delay || 0) / speed;
+            return realSetTimeout.apply(window, args);
+        };
+        window.setInterval = function (callback, delay) {
+            var args = Array.prototype.slice.call(arguments);
+            args[1] = (delay || 0) / speed;
+            return realSetInterval.apply(window, args);
+        };
+    }
+})();
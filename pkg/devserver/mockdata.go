@@ -0,0 +1,56 @@
+package devserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DataFile is the fixture of mock watch data looked up in the project directory
+const DataFile = "watchface.data.json"
+
+// defaultMockData feeds the data bridge when the project has no fixture
+const defaultMockData = `{
+  "battery": {"level": 76, "charging": false},
+  "steps": {"count": 6420, "goal": 10000},
+  "heart_rate": {"bpm": 72, "time": 1737425316000},
+  "weather": {"temperature": 21, "unit": "C", "condition": "cloudy", "high": 24, "low": 15, "location": "Shenzhen"},
+  "notifications": {"unread": 3}
+}`
+
+// loadMockData reads the data fixture, keyed by data type. A missing fixture
+// yields the default mock values.
+func loadMockData(dataPath string) (map[string]json.RawMessage, error) {
+	content, err := os.ReadFile(dataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		content = []byte(defaultMockData)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("invalid data fixture %s: %w", dataPath, err)
+	}
+	return data, nil
+}
+
+// mockHostScript returns the script that stands in for the watch firmware,
+// answering the bridge's WatchfaceHost.getData calls from the fixture
+func mockHostScript(data map[string]json.RawMessage) ([]byte, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`// Mock watch host injected by the dev server
+(function () {
+    var data = %s;
+    window.WatchfaceHost = {
+        getData: function (type) {
+            return type in data ? JSON.stringify(data[type]) : null;
+        }
+    };
+})();
+`, encoded)), nil
+}
@@ -0,0 +1,377 @@
+// Package devserver serves a watchface project for local development: the face is
+// shown inside a simulator page that frames it in a device's shape and size, and
+// reloads whenever a project file changes.
+package devserver
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/project"
+)
+
+// DefaultDevice is the device simulated when neither the configuration nor the
+// project selects one
+const DefaultDevice = "round-454"
+
+// devScriptsPath is the URL prefix of the scripts the dev server injects into faces
+const devScriptsPath = "/__dev/"
+
+// DefaultInterval is how often project files are polled for changes by default
+const DefaultInterval = 500 * time.Millisecond
+
+//go:embed simulator.html
+var simulatorHTML string
+
+// clockJS is the mockable clock shim, see clock.js
+//
+//go:embed clock.js
+var clockJS []byte
+
+var simulatorTemplate = template.Must(template.New("simulator").Parse(simulatorHTML))
+
+// Config contains options for the development server
+type Config struct {
+	ProjectPath string                                   // Project descriptor to serve
+	Device      string                                   // Device shown by default, otherwise the project's first device
+	DataPath    string                                   // Mock watch data fixture, default watchface.data.json in the project
+	Interval    time.Duration                            // How often project files are polled for changes
+	Logf        func(format string, args ...interface{}) // Receives reload and error messages, if set
+}
+
+// Server serves the simulator page, the generated face files and reload events
+type Server struct {
+	builder *builder.Builder
+	config  Config
+	mux     *http.ServeMux
+
+	mu      sync.Mutex
+	options builder.BuildOptions
+	loadErr error
+	faces   map[string]*face // Generated files by device ID
+	version int              // Incremented on every change
+	clients map[chan int]struct{}
+}
+
+// face is the result of generating the project for one device
+type face struct {
+	files map[string][]byte
+	err   error
+}
+
+// New creates a development server for the project and loads it once
+func New(b *builder.Builder, config Config) *Server {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.DataPath == "" {
+		config.DataPath = filepath.Join(filepath.Dir(config.ProjectPath), DataFile)
+	}
+	if config.Logf == nil {
+		config.Logf = func(string, ...interface{}) {}
+	}
+
+	s := &Server{
+		builder: b,
+		config:  config,
+		mux:     http.NewServeMux(),
+		clients: map[chan int]struct{}{},
+	}
+	s.mux.HandleFunc("/", s.handleSimulator)
+	s.mux.HandleFunc("/face/", s.handleFace)
+	s.mux.HandleFunc("/events", s.handleEvents)
+	s.mux.HandleFunc(devScriptsPath+"clock.js", serveScript(clockJS))
+	s.mux.HandleFunc(devScriptsPath+"host.js", s.handleHost)
+	s.reload()
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	s.mux.ServeHTTP(w, r)
+}
+
+// Err returns the error from loading the project, if any
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadErr
+}
+
+// Watch polls the project files until ctx is done, reloading the project and
+// notifying connected simulators whenever one changes
+func (s *Server) Watch(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	stamps := snapshot(s.watchRoots(), s.ignored())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := snapshot(s.watchRoots(), s.ignored())
+		changed := changedFiles(stamps, current)
+		stamps = current
+		if len(changed) == 0 {
+			continue
+		}
+
+		s.config.Logf("🔄 Changed: %s", strings.Join(s.relativePaths(changed), ", "))
+		s.reload()
+		if err := s.Err(); err != nil {
+			s.config.Logf("❌ %v", err)
+		} else if _, err := s.face(s.defaultDevice()); err != nil {
+			s.config.Logf("❌ %v", err)
+		}
+		s.broadcast()
+	}
+}
+
+// relativePaths shortens paths inside the project directory for display
+func (s *Server) relativePaths(paths []string) []string {
+	projectDir := filepath.Dir(s.config.ProjectPath)
+	shortened := make([]string, len(paths))
+	for i, p := range paths {
+		if rel, err := filepath.Rel(projectDir, p); err == nil && filepath.IsLocal(rel) {
+			p = rel
+		}
+		shortened[i] = p
+	}
+	return shortened
+}
+
+// reload reads the project descriptor again and drops every generated face
+func (s *Server) reload() {
+	options, err := loadOptions(s.config.ProjectPath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.options = options // Keep watching the last good layout while the descriptor is broken
+	}
+	s.loadErr = err
+	s.faces = map[string]*face{}
+	s.version++
+}
+
+// loadOptions loads and validates a project descriptor and returns its build options
+func loadOptions(projectPath string) (builder.BuildOptions, error) {
+	p, err := project.Load(projectPath)
+	if err != nil {
+		return builder.BuildOptions{}, err
+	}
+	if err := p.Validate(); err != nil {
+		return builder.BuildOptions{}, fmt.Errorf("invalid project file %s: %w", projectPath, err)
+	}
+	return p.BuildOptions()
+}
+
+// face returns the files generated for a device, generating them on first use.
+// The device is made the primary target, followed by the project's other devices.
+func (s *Server) face(deviceID string) (map[string][]byte, error) {
+	s.mu.Lock()
+	if s.loadErr != nil {
+		err := s.loadErr
+		s.mu.Unlock()
+		return nil, err
+	}
+	if f, ok := s.faces[deviceID]; ok {
+		s.mu.Unlock()
+		return f.files, f.err
+	}
+	options := s.options
+	options.Devices = []string{deviceID}
+	for _, id := range s.options.Devices {
+		if id != deviceID {
+			options.Devices = append(options.Devices, id)
+		}
+	}
+	version := s.version
+	s.mu.Unlock()
+
+	// Generate without the lock, so that other requests and reloads are not held up
+	files, err := s.builder.GenerateFiles(options)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.version == version { // Otherwise the project was reloaded meanwhile and the face is stale
+		s.faces[deviceID] = &face{files: files, err: err}
+	}
+	return files, err
+}
+
+// defaultDevice returns the device shown when the simulator does not ask for one
+func (s *Server) defaultDevice() string {
+	if s.config.Device != "" {
+		return s.config.Device
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.options.Devices) > 0 {
+		return s.options.Devices[0]
+	}
+	return DefaultDevice
+}
+
+// broadcast tells every connected simulator to reload
+func (s *Server) broadcast() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- s.version:
+		default: // The client already has a reload pending
+		}
+	}
+}
+
+// simulatorData is the data the simulator page is rendered with
+type simulatorData struct {
+	Name    string
+	Project string
+	Device  builder.DeviceProfile
+	Devices []builder.DeviceProfile
+	FaceURL string
+}
+
+// handleSimulator handles GET /?device=<id>
+func (s *Server) handleSimulator(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	deviceID := r.URL.Query().Get("device")
+	if deviceID == "" {
+		deviceID = s.defaultDevice()
+	}
+	device, ok := builder.LookupDevice(deviceID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown device: %s", deviceID), http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	name := s.options.Name
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = simulatorTemplate.Execute(w, simulatorData{
+		Name:    name,
+		Project: s.config.ProjectPath,
+		Device:  device,
+		Devices: builder.DeviceProfiles(),
+		FaceURL: "/face/" + device.ID + "/index.html",
+	})
+}
+
+// handleFace handles GET /face/<device>/<file>
+func (s *Server) handleFace(w http.ResponseWriter, r *http.Request) {
+	deviceID, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/face/"), "/")
+	if _, ok := builder.LookupDevice(deviceID); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if name == "" {
+		name = "index.html"
+	}
+
+	files, err := s.face(deviceID)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<!DOCTYPE html><html><body style="margin:0;padding:12px;font:13px monospace;color:#ff6b6b;background:#1a1a1a;white-space:pre-wrap">%s</body></html>`,
+			template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	content, ok := files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if ext := path.Ext(name); ext == ".html" || ext == ".htm" {
+		content = builder.InjectScripts(content, devScriptsPath+"clock.js", devScriptsPath+"host.js")
+	}
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	_, _ = w.Write(content)
+}
+
+// handleHost handles GET /__dev/host.js, the mock watch host that feeds the data
+// bridge from the fixture. The fixture is read on every request, and editing it
+// reloads the face like any other project file.
+func (s *Server) handleHost(w http.ResponseWriter, r *http.Request) {
+	data, err := loadMockData(s.config.DataPath)
+	if err == nil {
+		var script []byte
+		if script, err = mockHostScript(data); err == nil {
+			serveScript(script)(w, r)
+			return
+		}
+	}
+	s.config.Logf("❌ %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// serveScript returns a handler that serves an embedded script
+func serveScript(content []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		_, _ = w.Write(content)
+	}
+}
+
+// handleEvents handles GET /events, a server-sent event stream with a reload
+// event after every change
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan int, 1)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case version := <-client:
+			fmt.Fprintf(w, "event: reload\ndata: %d\n\n", version)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,222 @@
+package devserver
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+// newTestServer writes a project with the descriptor and returns a server for it
+func newTestServer(t *testing.T, descriptor string) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "watchface.yaml")
+	if err := os.WriteFile(projectPath, []byte(descriptor), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return New(builder.NewBuilder(), Config{ProjectPath: projectPath, Interval: 10 * time.Millisecond}), projectPath
+}
+
+// get sends a GET request to a server and returns the recorded response
+func get(s *Server, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+const testProject = "name: Dev Face\ntemplate: digital\ndevices: [round-454]\n"
+
+func TestSimulatorSwitchesDevice(t *testing.T) {
+	s, _ := newTestServer(t, testProject)
+
+	tests := []struct {
+		target, device string
+	}{
+		{"/", "round-454"},
+		{"/?device=square-320", "square-320"},
+	}
+	for _, tt := range tests {
+		recorder := get(s, tt.target)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", tt.target, recorder.Code)
+		}
+		device, _ := builder.LookupDevice(tt.device)
+		body := recorder.Body.String()
+		if !strings.Contains(body, "/face/"+tt.device+"/index.html") || !strings.Contains(body, device.Name) {
+			t.Errorf("GET %s does not show %s", tt.target, tt.device)
+		}
+	}
+
+	// Each device gets the face laid out for it, with the dev scripts injected
+	round := get(s, "/face/round-454/index.html").Body.String()
+	square := get(s, "/face/square-320/index.html").Body.String()
+	if round == square {
+		t.Error("round-454 and square-320 are served the same face")
+	}
+	for _, script := range []string{devScriptsPath + "clock.js", devScriptsPath + "host.js"} {
+		if !strings.Contains(square, script) {
+			t.Errorf("face does not load %s", script)
+		}
+	}
+	if recorder := get(s, "/face/square-320/style.css"); recorder.Code != http.StatusOK ||
+		!strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/css") {
+		t.Errorf("GET style.css = %d %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestNotFoundAndErrors(t *testing.T) {
+	s, projectPath := newTestServer(t, testProject)
+	for _, target := range []string{
+		"/missing",
+		"/?device=toaster",
+		"/face/toaster/index.html",
+		"/face/round-454/missing.js",
+	} {
+		if recorder := get(s, target); recorder.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", target, recorder.Code)
+		}
+	}
+
+	// A broken data fixture fails the mock host
+	dataPath := filepath.Join(filepath.Dir(projectPath), DataFile)
+	if err := os.WriteFile(dataPath, []byte(`{"battery": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if recorder := get(s, devScriptsPath+"host.js"); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("GET host.js with a broken fixture = %d, want 500", recorder.Code)
+	}
+
+	// A face that does not generate is shown as an error page
+	broken, _ := newTestServer(t, "name: Broken\ntemplate: nonexistent\n")
+	recorder := get(broken, "/face/round-454/index.html")
+	if recorder.Code != http.StatusInternalServerError || !strings.Contains(recorder.Body.String(), "nonexistent") {
+		t.Errorf("GET face of a broken project = %d %s", recorder.Code, recorder.Body)
+	}
+	if broken.Err() != nil {
+		t.Errorf("Err() = %v, want the project to load", broken.Err())
+	}
+
+	// So is a descriptor that does not load
+	invalid, _ := newTestServer(t, "template: digital\n")
+	if invalid.Err() == nil {
+		t.Error("Err() = nil for a project without a name")
+	}
+	if recorder := get(invalid, "/face/round-454/index.html"); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("GET face of an invalid project = %d, want 500", recorder.Code)
+	}
+}
+
+func TestReloadAfterChange(t *testing.T) {
+	s, projectPath := newTestServer(t, testProject)
+	httpServer := httptest.NewServer(s)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx)
+
+	response, err := http.Get(httpServer.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	events := bufio.NewReader(response.Body)
+	if line, err := events.ReadString('\n'); err != nil || line != "retry: 1000\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+
+	before := get(s, "/face/round-454/index.html").Body.String()
+	// Polling compares modification times and sizes, so the change grows the file
+	if err := os.WriteFile(projectPath, []byte(strings.Replace(testProject, "Dev Face", "Renamed Face", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	timeout := time.After(5 * time.Second)
+	for reloaded := false; !reloaded; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("event stream closed")
+			}
+			reloaded = line == "event: reload\n"
+		case <-timeout:
+			t.Fatal("no reload event after the project changed")
+		}
+	}
+
+	after := get(s, "/face/round-454/index.html").Body.String()
+	if after == before || !strings.Contains(after, "Renamed Face") {
+		t.Errorf("face was not regenerated after the change:\n%s", after)
+	}
+	if !strings.Contains(get(s, "/").Body.String(), "Renamed Face") {
+		t.Error("simulator still shows the old name")
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.js", "b.css", ".git/HEAD", "dist/face.zip"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dist := filepath.Join(dir, "dist")
+	before := snapshot([]string{dir}, dist)
+	if len(before) != 2 {
+		t.Fatalf("snapshot = %v, want a.js and b.css", before)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b.css")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "c.html"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dist, "face.zip"), []byte("rebuilt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := changedFiles(before, snapshot([]string{dir}, dist))
+	var names []string
+	for _, path := range changed {
+		names = append(names, filepath.Base(path))
+	}
+	if got, want := strings.Join(names, " "), "a.js b.css c.html"; got != want {
+		t.Errorf("changed = %s, want %s", got, want)
+	}
+}
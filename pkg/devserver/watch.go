@@ -0,0 +1,82 @@
+package devserver
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileStamp identifies a version of a file for change detection
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchRoots returns the directories polled for changes: the project directory
+// and the source directory when it lies outside the project
+func (s *Server) watchRoots() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projectDir := filepath.Dir(s.config.ProjectPath)
+	roots := []string{projectDir}
+	if s.options.SourceDir != "" && !within(projectDir, s.options.SourceDir) {
+		roots = append(roots, s.options.SourceDir)
+	}
+	return roots
+}
+
+// ignored returns the output directory, so that built packages do not trigger reloads
+func (s *Server) ignored() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.options.OutputPath
+}
+
+// snapshot stamps every file under the roots, skipping hidden directories and skip
+func snapshot(roots []string, skip string) map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Files may disappear while walking
+			}
+			if d.IsDir() {
+				if path != root && (strings.HasPrefix(d.Name(), ".") || path == skip) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return stamps
+}
+
+// changedFiles returns the sorted paths added, modified or removed between two snapshots
+func changedFiles(before, after map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if previous, ok := before[path]; !ok || previous != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
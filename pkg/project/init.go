@@ -0,0 +1,109 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"gopkg.in/yaml.v3"
+)
+
+// gitignore is written into every scaffolded project
+const gitignore = `# Build output
+dist/
+*.zip
+`
+
+// Init scaffolds an editable project in dir from a template.
+// The template's files are written as-is and referenced from a
+// watchface.yaml that builds them with the custom template, so the
+// directory can be edited and rebuilt with "watchface-builder build".
+func Init(dir string, tmpl builder.Template, options builder.BuildOptions, force bool) (*Project, error) {
+	if !force {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("directory %s is not empty (use --force to overwrite)", dir)
+		}
+	}
+
+	files, err := tmpl.Generate(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate template %s: %w", tmpl.ID(), err)
+	}
+	if _, ok := files["index.html"]; !ok {
+		return nil, fmt.Errorf("template %s did not produce index.html", tmpl.ID())
+	}
+
+	p := &Project{
+		Name:        options.Name,
+		Version:     options.Version,
+		Author:      options.Author,
+		Description: options.Description,
+		Tags:        options.Tags,
+		Template:    "custom",
+		Devices:     options.Devices,
+		Locales:     options.Locales,
+		HTMLFile:    "index.html",
+		dir:         dir,
+	}
+
+	assets := map[string]bool{}
+	for fileName := range files {
+		switch fileName {
+		case "index.html":
+		case "style.css":
+			p.CSSFile = fileName
+		case "script.js":
+			p.JSFile = fileName
+		default:
+			// Reference top-level entries so whole asset directories are packaged
+			assets[strings.SplitN(fileName, "/", 2)[0]] = true
+		}
+	}
+	for asset := range assets {
+		p.Assets = append(p.Assets, asset)
+	}
+	sort.Strings(p.Assets)
+
+	for fileName, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", fileName, err)
+		}
+		if err := os.WriteFile(filePath, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(gitignore), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+
+	header := fmt.Sprintf("# Watchface project scaffolded from the %q template.\n"+
+		"# Edit the files and rebuild with: watchface-builder build\n", tmpl.ID())
+	if err := p.Save(header); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Save writes the descriptor to watchface.yaml in the project directory, preceded by header
+func (p *Project) Save(header string) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("failed to encode project file: %w", err)
+	}
+
+	path := filepath.Join(p.dir, FileNames[0])
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write project file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+func TestInitOmitsEmptyTheme(t *testing.T) {
+	tmpl, ok := builder.DefaultRegistry().Lookup("analog")
+	if !ok {
+		t.Fatal("analog template is not registered")
+	}
+	dir := t.TempDir()
+	if _, err := Init(dir, tmpl, builder.BuildOptions{Name: "Scaffolded"}, false); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	path := filepath.Join(dir, FileNames[0])
+	descriptor, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(descriptor), "\n") {
+		if strings.HasPrefix(line, "theme:") {
+			t.Errorf("%s has a theme block:\n%s", FileNames[0], descriptor)
+		}
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.Theme != nil {
+		t.Errorf("loaded theme = %+v, want nil", *p.Theme)
+	}
+}
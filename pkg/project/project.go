@@ -0,0 +1,299 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames lists the project descriptor names searched for, in order of preference
+var FileNames = []string{"watchface.yaml", "watchface.yml", "watchface.json"}
+
+// Project is a watchface project descriptor (watchface.yaml or watchface.json).
+// File references are resolved relative to the directory containing the descriptor.
+type Project struct {
+	Name            string                 `yaml:"name" json:"name"`
+	Version         string                 `yaml:"version,omitempty" json:"version,omitempty"`
+	Author          string                 `yaml:"author,omitempty" json:"author,omitempty"`
+	Description     string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags            []string               `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Template        string                 `yaml:"template,omitempty" json:"template,omitempty"`
+	Devices         []string               `yaml:"devices,omitempty" json:"devices,omitempty"`
+	Permissions     []string               `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+	Complications   []builder.Complication `yaml:"complications,omitempty" json:"complications,omitempty"`
+	Locales         []string               `yaml:"locales,omitempty" json:"locales,omitempty"`
+	Theme           *builder.Theme         `yaml:"theme,omitempty" json:"theme,omitempty"`
+	ThemeFile       string                 `yaml:"themeFile,omitempty" json:"themeFile,omitempty"`
+	Font            string                 `yaml:"font,omitempty" json:"font,omitempty"`
+	CustomHTML      string                 `yaml:"customHTML,omitempty" json:"customHTML,omitempty"`
+	CustomCSS       string                 `yaml:"customCSS,omitempty" json:"customCSS,omitempty"`
+	CustomJS        string                 `yaml:"customJS,omitempty" json:"customJS,omitempty"`
+	HTMLFile        string                 `yaml:"htmlFile,omitempty" json:"htmlFile,omitempty"`
+	CSSFile         string                 `yaml:"cssFile,omitempty" json:"cssFile,omitempty"`
+	JSFile          string                 `yaml:"jsFile,omitempty" json:"jsFile,omitempty"`
+	Source          string                 `yaml:"source,omitempty" json:"source,omitempty"`
+	Include         []string               `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude         []string               `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	Assets          []string               `yaml:"assets,omitempty" json:"assets,omitempty"`
+	Output          string                 `yaml:"output,omitempty" json:"output,omitempty"`
+	GeneratePreview *bool                  `yaml:"generatePreview,omitempty" json:"generatePreview,omitempty"`
+	StoreImages     bool                   `yaml:"storeImages,omitempty" json:"storeImages,omitempty"`
+	PreviewTime     string                 `yaml:"previewTime,omitempty" json:"previewTime,omitempty"`
+	MinifyHTML      bool                   `yaml:"minifyHTML,omitempty" json:"minifyHTML,omitempty"`
+	MinifyCSS       bool                   `yaml:"minifyCSS,omitempty" json:"minifyCSS,omitempty"`
+	MinifyJS        bool                   `yaml:"minifyJS,omitempty" json:"minifyJS,omitempty"`
+	OptimizeImages  bool                   `yaml:"optimizeImages,omitempty" json:"optimizeImages,omitempty"`
+	Inline          bool                   `yaml:"inline,omitempty" json:"inline,omitempty"`
+	Reproducible    bool                   `yaml:"reproducible,omitempty" json:"reproducible,omitempty"`
+
+	dir string // Directory containing the descriptor
+}
+
+// Find returns the path of the project descriptor in dir
+func Find(dir string) (string, error) {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no project file (%s) found in %s", strings.Join(FileNames, ", "), dir)
+}
+
+// Load reads and parses a project descriptor
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	p := &Project{}
+	if err := decode(path, data, p); err != nil {
+		return nil, err
+	}
+
+	p.dir = filepath.Dir(path)
+	return p, nil
+}
+
+// LoadTheme reads a theme file (YAML, or JSON by its .json extension)
+func LoadTheme(path string) (builder.Theme, error) {
+	var theme builder.Theme
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return theme, fmt.Errorf("failed to read theme file: %w", err)
+	}
+	err = decode(path, data, &theme)
+	return theme, err
+}
+
+// decode parses YAML or JSON by the file extension, rejecting unknown fields
+func decode(path string, data []byte, v interface{}) error {
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(v)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Dir returns the directory file references are resolved against
+func (p *Project) Dir() string {
+	return p.dir
+}
+
+// Validate checks the descriptor for missing or conflicting fields
+func (p *Project) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.CustomHTML != "" && p.HTMLFile != "" {
+		return fmt.Errorf("customHTML and htmlFile are mutually exclusive")
+	}
+	if p.CustomCSS != "" && p.CSSFile != "" {
+		return fmt.Errorf("customCSS and cssFile are mutually exclusive")
+	}
+	if p.CustomJS != "" && p.JSFile != "" {
+		return fmt.Errorf("customJS and jsFile are mutually exclusive")
+	}
+
+	for _, ref := range []string{p.HTMLFile, p.CSSFile, p.JSFile, p.ThemeFile, p.Font} {
+		if ref == "" {
+			continue
+		}
+		if info, err := os.Stat(p.resolve(ref)); err != nil || info.IsDir() {
+			return fmt.Errorf("referenced file not found: %s", ref)
+		}
+	}
+	if p.Source != "" {
+		if info, err := os.Stat(p.resolve(p.Source)); err != nil || !info.IsDir() {
+			return fmt.Errorf("source directory not found: %s", p.Source)
+		}
+	}
+	for _, asset := range p.Assets {
+		if filepath.IsAbs(asset) || !filepath.IsLocal(asset) {
+			return fmt.Errorf("asset must be a relative path inside the project: %s", asset)
+		}
+		if _, err := os.Stat(p.resolve(asset)); err != nil {
+			return fmt.Errorf("asset not found: %s", asset)
+		}
+	}
+	return nil
+}
+
+// BuildOptions converts the descriptor into builder options, reading referenced files
+func (p *Project) BuildOptions() (builder.BuildOptions, error) {
+	options := builder.BuildOptions{
+		Name:            p.Name,
+		Version:         p.Version,
+		Author:          p.Author,
+		Description:     p.Description,
+		Tags:            p.Tags,
+		Template:        p.Template,
+		Devices:         p.Devices,
+		Permissions:     p.Permissions,
+		Complications:   p.Complications,
+		Locales:         p.Locales,
+		CustomHTML:      p.CustomHTML,
+		CustomCSS:       p.CustomCSS,
+		CustomJS:        p.CustomJS,
+		Include:         p.Include,
+		Exclude:         p.Exclude,
+		OutputPath:      p.Output,
+		GeneratePreview: true,
+		StoreImages:     p.StoreImages,
+		MinifyHTML:      p.MinifyHTML,
+		MinifyCSS:       p.MinifyCSS,
+		MinifyJS:        p.MinifyJS,
+		OptimizeImages:  p.OptimizeImages,
+		Inline:          p.Inline,
+		Reproducible:    p.Reproducible,
+	}
+	if options.Version == "" {
+		options.Version = "1.0.0"
+	}
+	if options.Author == "" {
+		options.Author = "Anonymous"
+	}
+	if options.Template == "" {
+		options.Template = "simple"
+	}
+	if options.OutputPath == "" {
+		options.OutputPath = "dist"
+	}
+	options.OutputPath = p.resolve(options.OutputPath)
+	if p.Source != "" {
+		options.SourceDir = p.resolve(p.Source)
+	}
+	if p.GeneratePreview != nil {
+		options.GeneratePreview = *p.GeneratePreview
+	}
+	if p.PreviewTime != "" {
+		at, err := builder.ParsePreviewTime(p.PreviewTime)
+		if err != nil {
+			return options, err
+		}
+		options.PreviewTime = at
+	}
+
+	if p.Font != "" {
+		content, err := os.ReadFile(p.resolve(p.Font))
+		if err != nil {
+			return options, fmt.Errorf("failed to read %s: %w", p.Font, err)
+		}
+		options.Font = content
+	}
+
+	// Fields of the inline theme take precedence over the theme file
+	if p.Theme != nil {
+		options.Theme = *p.Theme
+	}
+	if p.ThemeFile != "" {
+		theme, err := LoadTheme(p.resolve(p.ThemeFile))
+		if err != nil {
+			return options, err
+		}
+		options.Theme = theme.Merge(options.Theme)
+	}
+
+	refs := []struct {
+		path   string
+		target *string
+	}{
+		{p.HTMLFile, &options.CustomHTML},
+		{p.CSSFile, &options.CustomCSS},
+		{p.JSFile, &options.CustomJS},
+	}
+	for _, ref := range refs {
+		if ref.path == "" {
+			continue
+		}
+		content, err := os.ReadFile(p.resolve(ref.path))
+		if err != nil {
+			return options, fmt.Errorf("failed to read %s: %w", ref.path, err)
+		}
+		*ref.target = string(content)
+	}
+
+	assets, err := p.readAssets()
+	if err != nil {
+		return options, err
+	}
+	options.Assets = assets
+
+	return options, nil
+}
+
+// readAssets reads the asset files and directories, keyed by their project-relative path
+func (p *Project) readAssets() (map[string][]byte, error) {
+	if len(p.Assets) == 0 {
+		return nil, nil
+	}
+
+	assets := map[string][]byte{}
+	for _, asset := range p.Assets {
+		root := p.resolve(asset)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(p.dir, path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			assets[filepath.ToSlash(relPath)] = content
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %w", asset, err)
+		}
+	}
+	return assets, nil
+}
+
+// resolve resolves a file reference against the project directory
+func (p *Project) resolve(ref string) string {
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(p.dir, ref)
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+// maxRequestBody limits the size of a build request body
+const maxRequestBody = 10 << 20
+
+// buildIDLength is the length of the hex ID naming each build's artifact directory
+const buildIDLength = 32
+
+// Config contains options for the API server
+type Config struct {
+	ArtifactDir string   // Directory where built packages are stored and served from
+	AuthToken   string   // Bearer token required on every request, if set
+	CORSOrigins []string // Allowed CORS origins, empty allows all
+}
+
+// Server exposes the watchface builder as a REST API
+type Server struct {
+	builder *builder.Builder
+	config  Config
+	mux     *http.ServeMux
+}
+
+// New creates a new API server backed by the given builder
+func New(b *builder.Builder, config Config) *Server {
+	if config.ArtifactDir == "" {
+		config.ArtifactDir = "."
+	}
+
+	s := &Server{
+		builder: b,
+		config:  config,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/api/build", s.handleBuild)
+	s.mux.HandleFunc("/api/templates", s.handleTemplates)
+	s.mux.HandleFunc("/api/devices", s.handleDevices)
+	s.mux.HandleFunc("/api/download/", s.handleDownload)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// buildRequest is the JSON body accepted by POST /api/build
+type buildRequest struct {
+	Name            string                 `json:"name"`
+	Version         string                 `json:"version"`
+	Author          string                 `json:"author"`
+	Description     string                 `json:"description"`
+	Tags            []string               `json:"tags"`
+	Template        string                 `json:"template"`
+	Devices         []string               `json:"devices"`
+	Permissions     []string               `json:"permissions"`
+	Complications   []builder.Complication `json:"complications"`
+	Locales         []string               `json:"locales"`
+	Theme           builder.Theme          `json:"theme"`
+	Font            []byte                 `json:"font"` // Base64 in JSON
+	CustomHTML      string                 `json:"customHTML"`
+	CustomCSS       string                 `json:"customCSS"`
+	CustomJS        string                 `json:"customJS"`
+	GeneratePreview *bool                  `json:"generatePreview"`
+	StoreImages     bool                   `json:"storeImages"`
+	PreviewTime     string                 `json:"previewTime"`
+	MinifyHTML      bool                   `json:"minifyHTML"`
+	MinifyCSS       bool                   `json:"minifyCSS"`
+	MinifyJS        bool                   `json:"minifyJS"`
+	OptimizeImages  bool                   `json:"optimizeImages"`
+	Inline          bool                   `json:"inline"`
+	Reproducible    bool                   `json:"reproducible"`
+}
+
+// options maps the request onto builder options, applying documented defaults
+func (req buildRequest) options(outputPath string) (builder.BuildOptions, error) {
+	options := builder.BuildOptions{
+		Name:            req.Name,
+		Version:         req.Version,
+		Author:          req.Author,
+		Description:     req.Description,
+		Tags:            req.Tags,
+		Template:        req.Template,
+		Devices:         req.Devices,
+		Permissions:     req.Permissions,
+		Complications:   req.Complications,
+		Locales:         req.Locales,
+		Theme:           req.Theme,
+		Font:            req.Font,
+		CustomHTML:      req.CustomHTML,
+		CustomCSS:       req.CustomCSS,
+		CustomJS:        req.CustomJS,
+		OutputPath:      outputPath,
+		GeneratePreview: true,
+		StoreImages:     req.StoreImages,
+		MinifyHTML:      req.MinifyHTML,
+		MinifyCSS:       req.MinifyCSS,
+		MinifyJS:        req.MinifyJS,
+		OptimizeImages:  req.OptimizeImages,
+		Inline:          req.Inline,
+		Reproducible:    req.Reproducible,
+	}
+	if options.Version == "" {
+		options.Version = "1.0.0"
+	}
+	if options.Author == "" {
+		options.Author = "Anonymous"
+	}
+	if req.GeneratePreview != nil {
+		options.GeneratePreview = *req.GeneratePreview
+	}
+	if req.PreviewTime != "" {
+		at, err := builder.ParsePreviewTime(req.PreviewTime)
+		if err != nil {
+			return options, err
+		}
+		options.PreviewTime = at
+	}
+	return options, nil
+}
+
+// buildResponse is the JSON body returned by POST /api/build
+type buildResponse struct {
+	Success     bool                   `json:"success"`
+	ZipPath     string                 `json:"zipPath"`
+	DownloadURL string                 `json:"downloadURL"`
+	FileHash    string                 `json:"fileHash"`
+	Size        int64                  `json:"size"`
+	FileCount   int                    `json:"fileCount"`
+	Files       []string               `json:"files"`
+	Savings     []builder.AssetSavings `json:"savings,omitempty"`
+	Manifest    json.RawMessage        `json:"manifest"`
+}
+
+// errorResponse is the JSON body returned for every failed request
+type errorResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// handleBuild handles POST /api/build
+func (s *Server) handleBuild(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req buildRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.Template == "" {
+		writeError(w, http.StatusBadRequest, "template is required")
+		return
+	}
+
+	// Each build gets a directory of its own, so that concurrent builds of the
+	// same face, or reproducible builds sharing a timestamp, never share a path
+	buildID, err := newBuildID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	buildDir := filepath.Join(s.config.ArtifactDir, buildID)
+	options, err := req.options(buildDir)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.builder.Build(options)
+	if err != nil {
+		_ = os.RemoveAll(buildDir)
+		var optionsErr *builder.OptionsError
+		if errors.As(err, &optionsErr) {
+			writeError(w, http.StatusBadRequest, err.Error())
+		} else {
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	zipName := filepath.Base(result.ZipPath)
+	savings, _ := result.Metadata["asset_savings"].([]builder.AssetSavings)
+	writeJSON(w, http.StatusOK, buildResponse{
+		Success:     true,
+		ZipPath:     zipName,
+		DownloadURL: "/api/download/" + buildID + "/" + zipName,
+		FileHash:    result.FileHash,
+		Size:        result.Size,
+		FileCount:   result.FileCount,
+		Files:       result.Files,
+		Savings:     savings,
+		Manifest:    json.RawMessage(result.Manifest),
+	})
+}
+
+// handleTemplates handles GET /api/templates
+func (s *Server) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": s.builder.Registry().List(),
+	})
+}
+
+// handleDevices handles GET /api/devices
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"devices": builder.DeviceProfiles(),
+	})
+}
+
+// handleDownload handles GET /api/download/:id/:filename
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	buildID, fileName, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/download/"), "/")
+	if !validBuildID(buildID) {
+		writeError(w, http.StatusBadRequest, "invalid build ID")
+		return
+	}
+	if fileName == "" || fileName != filepath.Base(fileName) ||
+		strings.ContainsAny(fileName, `/\`) || !strings.HasSuffix(fileName, ".zip") {
+		writeError(w, http.StatusBadRequest, "invalid filename")
+		return
+	}
+
+	filePath := filepath.Join(s.config.ArtifactDir, buildID, fileName)
+	if info, err := os.Stat(filePath); err != nil || info.IsDir() {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	http.ServeFile(w, r, filePath)
+}
+
+// newBuildID returns a random ID for a build's artifact directory
+func newBuildID() (string, error) {
+	id := make([]byte, buildIDLength/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate build ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// validBuildID reports whether id could have come from newBuildID
+func validBuildID(id string) bool {
+	if len(id) != buildIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// setCORSHeaders adds CORS headers for allowed origins
+func (s *Server) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	if len(s.config.CORSOrigins) == 0 {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		for _, allowed := range s.config.CORSOrigins {
+			if origin != "" && origin == allowed {
+				header.Set("Access-Control-Allow-Origin", origin)
+				break
+			}
+		}
+	}
+	header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+}
+
+// authorized checks the bearer token when authentication is enabled
+func (s *Server) authorized(r *http.Request) bool {
+	if s.config.AuthToken == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AuthToken)) == 1
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response in the documented format
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Success: false, Error: message})
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziztechnology/WatchfaceBuilder/pkg/builder"
+)
+
+// request sends a request to a server and returns the recorded response
+func request(s *Server, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func build(t *testing.T, s *Server, body string) buildResponse {
+	t.Helper()
+	recorder := request(s, http.MethodPost, "/api/build", body)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("POST /api/build = %d %s", recorder.Code, recorder.Body)
+	}
+	var response buildResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestBuildAndDownload(t *testing.T) {
+	s := New(builder.NewBuilder(), Config{ArtifactDir: t.TempDir()})
+	response := build(t, s, `{"name": "Served", "template": "simple", "generatePreview": false}`)
+	if !response.Success || response.ZipPath != filepath.Base(response.DownloadURL) {
+		t.Fatalf("response = %+v", response)
+	}
+
+	recorder := request(s, http.MethodGet, response.DownloadURL, "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d %s", response.DownloadURL, recorder.Code, recorder.Body)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if int64(recorder.Body.Len()) != response.Size {
+		t.Errorf("downloaded %d bytes, want %d", recorder.Body.Len(), response.Size)
+	}
+}
+
+func TestBuildsDoNotShareArtifacts(t *testing.T) {
+	s := New(builder.NewBuilder(), Config{ArtifactDir: t.TempDir()})
+	// Reproducible builds of the same face get the same file name
+	body := `{"name": "Same", "template": "simple", "generatePreview": false, "reproducible": true}`
+	first := build(t, s, body)
+	second := build(t, s, body)
+	if first.ZipPath != second.ZipPath {
+		t.Fatalf("file names differ: %s, %s", first.ZipPath, second.ZipPath)
+	}
+	if first.DownloadURL == second.DownloadURL {
+		t.Fatalf("both builds are served from %s", first.DownloadURL)
+	}
+	for _, response := range []buildResponse{first, second} {
+		recorder := request(s, http.MethodGet, response.DownloadURL, "")
+		if recorder.Code != http.StatusOK || int64(recorder.Body.Len()) != response.Size {
+			t.Errorf("GET %s = %d, %d bytes, want %d bytes",
+				response.DownloadURL, recorder.Code, recorder.Body.Len(), response.Size)
+		}
+	}
+}
+
+func TestBuildRejectsInvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	s := New(builder.NewBuilder(), Config{ArtifactDir: dir})
+	tests := []struct {
+		name, body string
+	}{
+		{"missing template", `{"name": "Bad"}`},
+		{"malformed body", `{"name": `},
+		{"unknown locale", `{"name": "Bad", "template": "simple", "locales": ["xx-XX"]}`},
+		{"unknown device", `{"name": "Bad", "template": "simple", "devices": ["toaster"]}`},
+		{"invalid preview time", `{"name": "Bad", "template": "simple", "previewTime": "noon"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := request(s, http.MethodPost, "/api/build", tt.body)
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("POST /api/build = %d %s, want 400", recorder.Code, recorder.Body)
+			}
+			var response errorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Success || response.Error == "" {
+				t.Errorf("body = %s", recorder.Body)
+			}
+		})
+	}
+
+	// Failed builds leave no artifact directories behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("artifact directory has %d entries", len(entries))
+	}
+	if recorder := request(s, http.MethodGet, "/api/build", ""); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/build = %d, want 405", recorder.Code)
+	}
+}
+
+func TestListings(t *testing.T) {
+	s := New(builder.NewBuilder(), Config{ArtifactDir: t.TempDir()})
+	tests := []struct {
+		path, key, want string
+	}{
+		{"/api/templates", "templates", "analog"},
+		{"/api/devices", "devices", "round-454"},
+	}
+	for _, tt := range tests {
+		recorder := request(s, http.MethodGet, tt.path, "")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", tt.path, recorder.Code)
+		}
+		var body map[string][]struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, item := range body[tt.key] {
+			found = found || item.ID == tt.want
+		}
+		if !found {
+			t.Errorf("GET %s lacks %s: %s", tt.path, tt.want, recorder.Body)
+		}
+		if recorder := request(s, http.MethodPost, tt.path, ""); recorder.Code != http.StatusMethodNotAllowed {
+			t.Errorf("POST %s = %d, want 405", tt.path, recorder.Code)
+		}
+	}
+}
+
+func TestDownloadStaysInArtifactDir(t *testing.T) {
+	root := t.TempDir()
+	artifacts := filepath.Join(root, "artifacts")
+	buildID := strings.Repeat("a", buildIDLength)
+	for _, dir := range []string{filepath.Join(artifacts, buildID), filepath.Join(artifacts, "notanid")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret := []byte("PK private package contents")
+	for _, path := range []string{
+		filepath.Join(root, "secret.zip"),
+		filepath.Join(artifacts, "secret.zip"),
+		filepath.Join(artifacts, "notanid", "secret.zip"),
+	} {
+		if err := os.WriteFile(path, secret, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(builder.NewBuilder(), Config{ArtifactDir: artifacts})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/download/secret.zip", http.StatusBadRequest},
+		{"/api/download/notanid/secret.zip", http.StatusBadRequest},
+		{"/api/download/" + buildID + "/..%2fsecret.zip", http.StatusMovedPermanently},
+		{"/api/download/" + buildID + "/..%5Csecret.zip", http.StatusBadRequest},
+		{"/api/download/" + buildID + "/../secret.zip", http.StatusMovedPermanently},
+		{"/api/download/" + buildID + "/../../secret.zip", http.StatusMovedPermanently},
+		{"/api/download/" + buildID + "/missing.zip", http.StatusNotFound},
+		{"/api/download/" + buildID + "/", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		recorder := request(s, http.MethodGet, tt.path, "")
+		if recorder.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, recorder.Code, tt.want)
+		}
+		if bytes.Contains(recorder.Body.Bytes(), secret) {
+			t.Errorf("GET %s served a file outside the build directories", tt.path)
+		}
+	}
+}